@@ -0,0 +1,15 @@
+module github.com/jayjanssen/myq-tools
+
+go 1.22
+
+require (
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/term v0.27.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jayjanssen/myq-tools/myqlib"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Exit codes
+const (
+	OK int = iota
+	BAD_ARGS
+	LOADER_ERROR
+)
+
+// Current Version (passed in on build)
+var build_version string
+var build_timestamp string
+
+func main() {
+	// Parse arguments
+	help := flag.Bool("help", false, "this help text")
+	version := flag.Bool("version", false, "print the version")
+
+	by := flag.String("by", strings.Join(myqlib.ProcessGroupings, ","), fmt.Sprint("count threads by these, any of ", strings.Join(myqlib.ProcessGroupings, ", ")))
+	sortby := flag.String("sort", myqlib.SORT_COUNT, "sort the counts by 'count' (threads), 'active' (threads not sleeping), 'time' (longest running) or 'name'")
+	limit := flag.Int("limit", 10, "show at most this many rows of each count and of the longest running queries")
+
+	mysql_args := flag.String("mysqlargs", "", "Arguments to pass to the mysql cli (used for connection options).  Note that '-p' for a password prompt is not supported.")
+	flag.StringVar(mysql_args, "a", "", "Short for -mysqlargs")
+	reconnect := flag.Int("reconnect", 0, "Try reconnecting to mysql this many times in a row if the connection is lost (default: 0, exit)")
+	backoff := flag.Duration("backoff", time.Second, "Time to wait before the first reconnect attempt, doubled for each attempt after that")
+	dsn := flag.String("dsn", "", "Connect natively with this DSN (example: 'user:pass@tcp(host:3306)/') instead of using the mysql cli")
+	interval := flag.Duration("interval", time.Second, "Time between samples (example: 1s or 1h30m)")
+	flag.DurationVar(interval, "i", time.Second, "short for -interval")
+
+	var sources []string
+	for name := range myqlib.ProcesslistSources {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+	source := flag.String("source", "processlist", fmt.Sprint("where to get the threads from a live server: ", strings.Join(sources, ", "), " (performance_schema is the lightest on busy servers)"))
+
+	file := flag.String("file", "", "parse SHOW FULL PROCESSLIST output (tabular, batch with column names or vertical, like pt-stalk's processlist files) instead of connecting to mysql ('-' for stdin, may be compressed)")
+	flag.StringVar(file, "f", "", "short for -file")
+
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("myq-tools %s (%s)\n", build_version, build_timestamp)
+		os.Exit(OK)
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "myq-tools %s (%s)\n\n", build_version, build_timestamp)
+
+		fmt.Fprint(os.Stderr, "Usage:\n  myq_processlist [flags]\n\n")
+		fmt.Fprint(os.Stderr, "Description:\n  top-like summaries of the MySQL processlist\n\n")
+
+		fmt.Fprintln(os.Stderr, "Options:")
+		flag.PrintDefaults()
+		os.Exit(BAD_ARGS)
+	}
+
+	if *help || flag.NArg() != 0 {
+		flag.Usage()
+	}
+
+	if interval.Seconds() < 1 {
+		fmt.Fprintln(os.Stderr, "Error: interval must be >= 1s")
+		flag.Usage()
+	}
+
+	top, err := myqlib.NewProcessTop(strings.Split(*by, ","), *sortby, *limit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		flag.Usage()
+	}
+
+	query, ok := myqlib.ProcesslistSources[*source]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Error: unknown -source:", *source)
+		flag.Usage()
+	}
+
+	var loader myqlib.Loader
+	if *file != "" {
+		loader = myqlib.NewProcesslistFileLoader(*interval, *file)
+	} else if *dsn != "" {
+		sqlloader := myqlib.NewSqlLoader(*interval, *dsn)
+		sqlloader.SetReconnect(*reconnect, *backoff)
+		sqlloader.SetExtraQueries(myqlib.ProcesslistQuery(query))
+		loader = sqlloader
+	} else {
+		liveloader := myqlib.NewLiveLoader(*interval, *mysql_args)
+		liveloader.SetReconnect(*reconnect, *backoff)
+		liveloader.SetExtraQueries(myqlib.ProcesslistQuery(query))
+		loader = liveloader
+	}
+
+	states, errs, err := myqlib.GetState(loader)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(LOADER_ERROR)
+	}
+
+	// Redraw the screen like top on a terminal, otherwise one summary after another
+	terminal := myqlib.IsTerminal(os.Stdout)
+	resize := func() {
+		if terminal {
+			_, width := myqlib.GetTermSize()
+			top.SetWidth(width)
+		} else {
+			top.SetWidth(math.MaxInt32)
+		}
+	}
+	resize()
+	resized := myqlib.NotifyResize()
+
+	first := true
+	for {
+		var state *myqlib.MyqState
+		select {
+		case <-resized:
+			resize()
+			continue
+		case err := <-errs:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(LOADER_ERROR)
+		case next, ok := <-states:
+			if !ok {
+				// The loader may have stopped because of an error, check for one before exiting
+				select {
+				case err := <-errs:
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(LOADER_ERROR)
+				default:
+					os.Exit(OK)
+				}
+			}
+			state = next
+		}
+
+		if terminal {
+			fmt.Print("\x1b[H\x1b[2J")
+		} else if !first {
+			fmt.Println()
+		}
+		first = false
+		if err := top.Render(os.Stdout, state); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(LOADER_ERROR)
+		}
+	}
+}
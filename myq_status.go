@@ -5,11 +5,15 @@ import (
 	"flag"
 	"fmt"
 	"github.com/jayjanssen/myq-tools/myqlib"
+	"io"
 	"math"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime/pprof"
 	"sort"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -19,8 +23,13 @@ const (
 	OK int = iota
 	BAD_ARGS
 	LOADER_ERROR
+	SERVE_ERROR
+	SINK_ERROR
 )
 
+// Format of -start and -end times
+const TIMESTAMP_LAYOUT string = "2006-01-02 15:04:05"
+
 // Current Version (passed in on build)
 var build_version string
 var build_timestamp string
@@ -32,17 +41,65 @@ func main() {
 
 	profile := flag.String("profile", "", "enable profiling and store the result in this file")
 	header := flag.Int64("header", 0, "repeat the header after this many data points (default: 0, autocalculates)")
+	output := flag.String("output", myqlib.TEXT_OUTPUT, "output format: 'text', 'json' for one JSON object of raw values per sample (JSON Lines), or 'csv' or 'tsv' for a header row and then a row of raw values per sample")
+	serve := flag.String("serve", "", "also serve the columns of every view as Prometheus metrics on /metrics at this address (example: ':9104')")
+	influx := flag.String("influx", "", "also write the view's values as InfluxDB line protocol to '-' (stdout, instead of the normal output), a file, or tcp://host:port or udp://host:port")
+	graphite := flag.String("graphite", "", "also write the view's values in the Graphite plaintext format to '-' (stdout, instead of the normal output), a file, or tcp://host:port or udp://host:port")
+	cols := flag.String("cols", "", "show these columns instead of a view, like 'rate:com_select,gauge:threads_running,pct:innodb_buffer_pool_pages_dirty/innodb_buffer_pool_pages_total' (kinds are gauge, rate, diff, pct, string and expr, like 'expr:bytes_sent/questions', variables can be regexes)")
+	viewfile := flag.String("viewfile", "", fmt.Sprint("load more views (or override the built-in ones) from this YAML file (default: ~/", myqlib.DEFAULT_VIEWFILE, " if it exists)"))
 	width := flag.Bool("width", false, "Truncate the output based on the width of the terminal")
+	check := flag.String("check", "", "Nagios check mode: average these columns over -count samples and exit with the worst status, like 'innodb.Hist>500000,cttf.run>32:64' (one number is critical, 'warn:crit' gives both, no threshold uses the column's own)")
+	count := flag.Int("count", 1, "with -check, how many samples to average (after the first, which rates need as a baseline)")
+	interactive := flag.Bool("interactive", false, "full screen mode: switch views with n/p, pause with space, aggregate more or fewer samples into each row with +/-, scroll back with k/j and quit with q")
 
 	mysql_args := flag.String("mysqlargs", "", "Arguments to pass to the mysql cli (used for connection options).  Note that '-p' for a password prompt is not supported.")
 	flag.StringVar(mysql_args, "a", "", "Short for -mysqlargs")
+	reconnect := flag.Int("reconnect", 0, "Try reconnecting to mysql this many times in a row if the connection is lost (default: 0, exit)")
+	backoff := flag.Duration("backoff", time.Second, "Time to wait before the first reconnect attempt, doubled for each attempt after that")
+	repl := flag.Bool("repl", false, "also collect SHOW REPLICA STATUS (SHOW SLAVE STATUS before 8.0.22) from live servers, for the repl view (on by default for it)")
+	innodbstatus := flag.Bool("innodbstatus", false, "also collect SHOW ENGINE INNODB STATUS from live servers, for the innodb_status view (on by default for it)")
+	waits := flag.Bool("waits", false, "also collect performance_schema wait event summaries from live servers, for the waits view (on by default for it)")
+	digests := flag.Bool("digests", false, "also collect performance_schema statement digests from live servers, for the digests view (on by default for it)")
+	dsn := flag.String("dsn", "", "Connect natively with this DSN (example: 'user:pass@tcp(host:3306)/') instead of using the mysql cli")
 	interval := flag.Duration("interval", time.Second, "Time between samples (example: 1s or 1h30m)")
 	flag.DurationVar(interval, "i", time.Second, "short for -interval")
 
-	statusfile := flag.String("file", "", "parse mysqladmin ext output file instead of connecting to mysql")
+	statusfile := flag.String("file", "", "parse mysqladmin ext output file instead of connecting to mysql ('-' for stdin, may be gzip, bzip2 or zstd compressed, sample times need pt-stalk style TS lines, which 'mysqladmin ext -i' doesn't write)")
 	flag.StringVar(statusfile, "f", "", "short for -file")
-	varfile := flag.String("varfile", "", "parse mysqladmin variables file instead of connecting to mysql, for optional use with -file")
+	varfile := flag.String("varfile", "", "parse mysqladmin variables file instead of connecting to mysql, for optional use with -file (may be compressed too)")
 	flag.StringVar(varfile, "vf", "", "short for -varfile")
+	skip := flag.Duration("skip", 0, "with -file or -stalk, skip samples until this far into the file (by uptime, example: 1h30m, the -varfile has no uptime and is read from its start)")
+	start := flag.String("start", "", "with -file or -stalk, skip samples taken before this time (example: '2014-10-24 13:34:40', needs TS lines in the file, and in the -varfile to skip its samples too)")
+	end := flag.String("end", "", "with -file or -stalk, stop at samples taken after this time, or this far into the file (by uptime) if given a duration")
+	stalkdir := flag.String("stalk", "", "load the status and variables pt-stalk collected in this directory instead of connecting to mysql (sample times start from the TS lines in its variables and processlist files, without them from the trigger prefix read in this host's time zone)")
+	trigger := flag.String("trigger", "", "with -stalk, the prefix of the files to load (example: 2014_10_24_13_34_40, defaults to the latest)")
+	follow := flag.Bool("follow", false, "with -file, keep waiting for more samples at the end of the file like 'tail -F' (the file can't be compressed)")
+	record := flag.String("record", "", "record the samples to this file (variables go to <file>.vars) for later use with -file and -varfile")
+
+	// Nagios takes any exit code as a status, so -check reports its own bad arguments as UNKNOWN
+	checking := func() bool {
+		if *check != "" {
+			return true
+		}
+		for _, arg := range os.Args[1:] { // flag.Parse may not have gotten to -check yet
+			name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+			if strings.HasPrefix(arg, "-") && name == "check" {
+				return true
+			}
+		}
+		return false
+	}
+	check_unknown := func(msg ...interface{}) {
+		fmt.Println(myqlib.CHECK_SERVICE, "UNKNOWN -", strings.TrimSuffix(fmt.Sprintln(msg...), "\n"))
+		os.Exit(myqlib.CHECK_UNKNOWN)
+	}
+	default_usage := flag.Usage
+	flag.Usage = func() {
+		if checking() {
+			check_unknown("bad arguments, see", os.Args[0], "-help")
+		}
+		default_usage()
+	}
 
 	flag.Parse()
 
@@ -69,14 +126,40 @@ func main() {
 		os.Exit(OK)
 	}
 
-	// Load default Views
-	views := myqlib.DefaultViews()
+	// Load default Views, with any user views on top
+	loadViews := func() map[string]myqlib.View {
+		views := myqlib.DefaultViews()
+		filename := *viewfile
+		if filename == "" {
+			home := filepath.Join(os.Getenv("HOME"), myqlib.DEFAULT_VIEWFILE)
+			if _, err := os.Stat(home); err == nil {
+				filename = home
+			}
+		}
+		if filename != "" {
+			userviews, err := myqlib.LoadViews(filename)
+			if err != nil && checking() {
+				check_unknown("bad view file:", err)
+			} else if err != nil {
+				fmt.Fprintln(os.Stderr, "Error: bad view file:", err)
+				os.Exit(BAD_ARGS)
+			}
+			for name, view := range userviews {
+				views[name] = view
+			}
+		}
+		return views
+	}
+	views := loadViews()
 
 	flag.Usage = func() {
+		if checking() {
+			check_unknown("bad arguments, see", os.Args[0], "-help")
+		}
 		fmt.Fprintf( os.Stderr, "myq-tools %s (%s)\n\n", build_version, build_timestamp )
 
-		fmt.Fprintln(os.Stderr, "Usage:\n  myq_status [flags] <view>\n")
-		fmt.Fprintln(os.Stderr, "Description:\n  iostat-like views for MySQL servers\n")
+		fmt.Fprint(os.Stderr, "Usage:\n  myq_status [flags] <view>\n  myq_status [flags] -cols <kind:variable,...>\n\n")
+		fmt.Fprint(os.Stderr, "Description:\n  iostat-like views for MySQL servers\n\n")
 
 		fmt.Fprintln(os.Stderr, "Options:")
 		flag.PrintDefaults()
@@ -100,23 +183,61 @@ func main() {
 		os.Exit(BAD_ARGS)
 	}
 
-	if flag.NArg() != 1 {
+	// Explain what's wrong with the arguments, then the usage
+	bad_args := func(msg ...interface{}) {
+		if checking() {
+			check_unknown(msg...)
+		}
+		fmt.Fprintln(os.Stderr, append([]interface{}{"Error:"}, msg...)...)
 		flag.Usage()
 	}
 
-	if interval.Seconds() < 1 {
-		fmt.Fprintln(os.Stderr, "Error: interval must be >= 1s")
+	if flag.NArg() != 1 && !(*cols != "" && flag.NArg() == 0) && !((*interactive || *check != "") && flag.NArg() == 0) {
 		flag.Usage()
+	}
+
+	if interval.Seconds() < 1 {
+		bad_args("interval must be >= 1s")
 	} else if math.Mod(float64(interval.Nanoseconds()), 1000000000) != 0.0 {
 		fmt.Fprintln(os.Stderr, "Warning: interval will be rounded to",
 			fmt.Sprintf("%.0f", interval.Seconds()), "seconds")
 	}
 
+	if *statusfile == "-" && *varfile == "-" {
+		bad_args("-file and -varfile can't both be read from stdin")
+	}
+	if *stalkdir != "" && (*statusfile != "" || *varfile != "") {
+		bad_args("-stalk can't be used with -file or -varfile")
+	}
+	if *follow && (*statusfile == "" || *statusfile == "-") {
+		bad_args("-follow needs a -file to follow")
+	}
+
+	switch *output {
+	case myqlib.TEXT_OUTPUT, myqlib.JSON_OUTPUT, myqlib.CSV_OUTPUT, myqlib.TSV_OUTPUT:
+	default:
+		bad_args("unknown -output", *output)
+	}
+
+	if *interactive && (*output != myqlib.TEXT_OUTPUT || *influx == myqlib.STDOUT_DEST || *graphite == myqlib.STDOUT_DEST) {
+		bad_args("-interactive needs stdout, so it can't be used with -output or a sink to '-'")
+	}
+
 	view := flag.Arg(0)
+	if view == "" && (*interactive || *check != "") && *cols == "" {
+		view = "cttf" // start somewhere
+	}
 	v, ok := views[view]
-	if !ok {
-		fmt.Fprintln(os.Stderr, "Error: view", view, "not found")
-		flag.Usage()
+	if *cols != "" {
+		// Build an ad-hoc view instead
+		colsview, err := myqlib.NewColsView(*cols)
+		if err != nil {
+			bad_args("bad -cols:", err)
+		}
+		view, v = "cols", colsview
+		views[view] = v
+	} else if !ok {
+		bad_args("view", view, "not found")
 	}
 
 	if *help {
@@ -134,7 +255,9 @@ func main() {
 
 	// How many lines before printing a new header
 	var headernum int64
-	if *header != 0 {
+	if *output != myqlib.TEXT_OUTPUT {
+		headernum = math.MaxInt64 // Only text repeats its header
+	} else if *header != 0 {
 		headernum = *header // Use the specified header count
 	} else {
 		termheight, termwidth := myqlib.GetTermSize()
@@ -142,36 +265,319 @@ func main() {
 		headernum = termheight
 	}
 
+	// Replication, InnoDB status, digests and waits are other queries (that need REPLICATION CLIENT, PROCESS and performance_schema), so only when they're wanted
+	collect_repl := *repl || view == "repl" || strings.Contains(*check, "repl.")
+	collect_innodb_status := *innodbstatus || view == "innodb_status" || strings.Contains(*check, "innodb_status.")
+	collect_digests := *digests || view == "digests" || strings.Contains(*check, "digests.")
+	collect_waits := *waits || view == "waits" || strings.Contains(*check, "waits.")
+	var extra_queries []myqlib.ExtraQuery
+	for _, c := range []struct {
+		collect bool
+		query   myqlib.ExtraQuery
+	}{
+		{collect_repl, myqlib.ReplicationQuery},
+		{collect_innodb_status, myqlib.InnodbStatusQuery},
+		{collect_digests, myqlib.DigestsQuery},
+		{collect_waits, myqlib.WaitsQuery},
+	} {
+		if c.collect {
+			extra_queries = append(extra_queries, c.query)
+		}
+	}
+
 	// The Loader and Timecol we will use
 	var loader myqlib.Loader
+	var timecol *myqlib.Col
 
-	if *statusfile != "" {
+	if *statusfile != "" || *stalkdir != "" {
 		// File given, load it (and the optional varfile)
-		loader = myqlib.NewFileLoader(*interval, *statusfile, *varfile)
-		v.SetTimeCol(&myqlib.Runtime_col)
+		var fileloader interface {
+			SetWindow(myqlib.SampleWindow)
+			SetFollow(bool)
+		}
+		if *stalkdir != "" {
+			// pt-stalk knows where its files are
+			stalkloader, err := myqlib.NewStalkLoader(*interval, *stalkdir, *trigger)
+			if err != nil && checking() {
+				check_unknown(err)
+			} else if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(LOADER_ERROR)
+			}
+			fileloader, loader = stalkloader, stalkloader
+		} else {
+			plainloader := myqlib.NewFileLoader(*interval, *statusfile, *varfile)
+			fileloader, loader = plainloader, plainloader
+		}
+
+		// Limit the samples we use
+		window := myqlib.SampleWindow{Skip: *skip}
+		if *start != "" {
+			t, err := time.ParseInLocation(TIMESTAMP_LAYOUT, *start, time.Local)
+			if err != nil {
+				bad_args("bad -start:", err)
+			}
+			window.Start = t
+		}
+		if *end != "" {
+			if d, err := time.ParseDuration(*end); err == nil {
+				window.Until = d
+			} else if t, err := time.ParseInLocation(TIMESTAMP_LAYOUT, *end, time.Local); err == nil {
+				window.End = t
+			} else {
+				bad_args("bad -end:", err)
+			}
+		}
+		fileloader.SetWindow(window)
+		fileloader.SetFollow(*follow)
+
+		timecol = &myqlib.Capturetime_col
+	} else if *dsn != "" {
+		// Live collection over the MySQL protocol, no mysql cli needed
+		sqlloader := myqlib.NewSqlLoader(*interval, *dsn)
+		sqlloader.SetReconnect(*reconnect, *backoff)
+		sqlloader.SetExtraQueries(extra_queries...)
+		loader = sqlloader
+		timecol = &myqlib.Timestamp_col
 	} else {
 		// No file given, this is a live collection and we use timestamps
-		loader = myqlib.NewLiveLoader(*interval, *mysql_args)
-		v.SetTimeCol(&myqlib.Timestamp_col)
+		liveloader := myqlib.NewLiveLoader(*interval, *mysql_args)
+		liveloader.SetReconnect(*reconnect, *backoff)
+		liveloader.SetExtraQueries(extra_queries...)
+		loader = liveloader
+		timecol = &myqlib.Timestamp_col
+	}
+
+	v.SetTimeCol(timecol)
+
+	// Tee the samples to disk if asked
+	if *record != "" {
+		loader = myqlib.NewRecordingLoader(loader, *record, fmt.Sprint(*record, ".vars"))
 	}
 
 	// Get channel that will feed us states from the loader
-	states, err := myqlib.GetState(loader)
-	if err != nil {
+	states, errs, err := myqlib.GetState(loader)
+	if err != nil && *check != "" {
+		fmt.Println(myqlib.CHECK_SERVICE, "UNKNOWN -", err)
+		os.Exit(myqlib.CHECK_UNKNOWN)
+	} else if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(LOADER_ERROR)
 	}
 
+	// Check thresholds and exit with a Nagios status instead of showing a view
+	if *check != "" {
+		unknown := func(err error) {
+			fmt.Println(myqlib.CHECK_SERVICE, "UNKNOWN -", err)
+			os.Exit(myqlib.CHECK_UNKNOWN)
+		}
+		checker, err := myqlib.NewChecker(*check, views)
+		if err != nil {
+			unknown(err)
+		}
+		for checker.Samples() < *count {
+			select {
+			case err := <-errs:
+				unknown(err)
+			case state, ok := <-states:
+				if !ok {
+					// The loader may have stopped because of an error, otherwise check what we have
+					select {
+					case err := <-errs:
+						unknown(err)
+					default:
+					}
+					states = nil
+					*count = checker.Samples()
+					continue
+				}
+				checker.Add(state)
+			}
+		}
+		status, line := checker.Result()
+		fmt.Println(line)
+		os.Exit(status)
+	}
+
+	// Export all the views while we output the selected one
+	var exporter *myqlib.Exporter
+	if *serve != "" {
+		exporter = myqlib.NewExporter(loadViews())
+		http.Handle("/metrics", exporter)
+		go func() {
+			if err := http.ListenAndServe(*serve, nil); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(SERVE_ERROR)
+			}
+		}()
+	}
+
+	// Send the view's values to any sinks as well
+	var sinks []myqlib.Sink
+	quiet := false // a sink is using stdout
+	if *influx != "" || *graphite != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(SINK_ERROR)
+		}
+		open := func(dest string) io.WriteCloser {
+			w, err := myqlib.OpenSinkDest(dest)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(SINK_ERROR)
+			}
+			quiet = quiet || dest == myqlib.STDOUT_DEST
+			return w
+		}
+		if *influx != "" {
+			sinks = append(sinks, myqlib.NewInfluxSink(open(*influx), hostname, view))
+		}
+		if *graphite != "" {
+			sinks = append(sinks, myqlib.NewGraphiteSink(open(*graphite), hostname, view))
+		}
+	}
+
+	// Color values past their thresholds if someone's watching
+	myqlib.SetColor(*output == myqlib.TEXT_OUTPUT && !quiet && myqlib.IsTerminal(os.Stdout))
+
+	// Switch between all the views on one stream of states
+	if *interactive {
+		// The TUI stops at the first error from the loader or a sink, so these never wait to send one
+		tuierrs := make(chan error, 1)
+		tui_error := func(err error) {
+			select {
+			case tuierrs <- err:
+			default: // there's one to stop at already
+			}
+		}
+		go func() {
+			tui_error(<-errs)
+		}()
+
+		// Exporter and sinks still get every state
+		shown := make(chan *myqlib.MyqState)
+		go func() {
+			defer close(shown)
+			for state := range states {
+				if exporter != nil {
+					exporter.Update(state)
+				}
+				for _, sink := range sinks {
+					if err := sink.Write(v, state); err != nil {
+						tui_error(err)
+						return
+					}
+				}
+				shown <- state
+			}
+		}()
+
+		tui := myqlib.NewTUI(views, view, timecol)
+		if *statusfile == "" && *stalkdir == "" {
+			// Live loaders only run the other queries for the first view (or with their flag), so say what the rest need
+			for _, c := range []struct {
+				view, flag string
+				collected  bool
+			}{
+				{"repl", "-repl", collect_repl},
+				{"innodb_status", "-innodbstatus", collect_innodb_status},
+				{"digests", "-digests", collect_digests},
+				{"waits", "-waits", collect_waits},
+			} {
+				if !c.collected {
+					tui.SetNote(c.view, fmt.Sprint("(not collected, start with ", c.flag, ")"))
+				}
+			}
+		}
+		if err := tui.Run(shown, tuierrs); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(LOADER_ERROR)
+		}
+		os.Exit(OK)
+	}
+
 	// Apply selected view to output each sample
 	lines := int64(0)
 	var buf myqlib.FixedWidthBuffer
-	if *width == true {
+	if *width == true && *output == myqlib.TEXT_OUTPUT {
 		termheight, termwidth := myqlib.GetTermSize()
 		_ = termheight
 		buf.SetWidth(termwidth)
 	}
 
-	for state := range states {
+	// Redraw the header to fit the terminal whenever it's resized
+	resized := myqlib.NotifyResize()
+
+	for {
+		var state *myqlib.MyqState
+		select {
+		case <-resized:
+			termheight, termwidth = myqlib.GetTermSize()
+			if *width == true {
+				buf.SetWidth(termwidth)
+			}
+			// Only text repeats its header, CSV/TSV has one at the top of the file
+			if *output == myqlib.TEXT_OUTPUT {
+				if *header == 0 {
+					headernum = termheight
+				}
+				lines = 0
+			}
+			continue
+		case err := <-errs:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(LOADER_ERROR)
+		case next, ok := <-states:
+			if !ok {
+				// The loader may have stopped because of an error, check for one before exiting
+				select {
+				case err := <-errs:
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(LOADER_ERROR)
+				default:
+					os.Exit(OK)
+				}
+			}
+			state = next
+		}
+
+		if exporter != nil {
+			exporter.Update(state)
+		}
+		for _, sink := range sinks {
+			if err := sink.Write(v, state); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(SINK_ERROR)
+			}
+		}
+		if quiet {
+			continue
+		}
+
+		// Machine readable output has no units, widths or repeated headers
+		if *output != myqlib.TEXT_OUTPUT {
+			var err error
+			switch *output {
+			case myqlib.JSON_OUTPUT:
+				err = myqlib.WriteJSON(os.Stdout, v, state)
+			case myqlib.CSV_OUTPUT, myqlib.TSV_OUTPUT:
+				comma := myqlib.OutputDelimiter(*output)
+				if lines == 0 {
+					err = myqlib.WriteDelimitedHeader(os.Stdout, v, state, comma)
+					lines += 1
+				}
+				if err == nil {
+					err = myqlib.WriteDelimited(os.Stdout, v, state, comma)
+				}
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(LOADER_ERROR)
+			}
+			continue
+		}
+
 		// Reprint a header whenever lines == 0
 		if lines == 0 {
 			headers := []string{}
@@ -195,17 +601,6 @@ func main() {
 		// Determine if we need to reset lines to 0 (and trigger a header)
 		if lines/headernum >= 1 {
 			lines = 0
-			// Recalculate the size of the terminal now too
-			if *width == true {
-				termheight, termwidth = myqlib.GetTermSize()
-				buf.SetWidth(termwidth)
-			}
-			if *header == 0 {
-				termheight, termwidth = myqlib.GetTermSize()
-				headernum = termheight
-			}
 		}
 	}
-
-	os.Exit(OK)
 }
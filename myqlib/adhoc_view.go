@@ -0,0 +1,136 @@
+package myqlib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Narrowest column in a ColsView, so numbers have room even under short names
+const MIN_ADHOC_WIDTH int64 = 5
+
+// One 'kind:variable' from a -cols spec
+type colSpec struct {
+	kind, variable string
+}
+
+// A view built from a spec like 'rate:com_select,gauge:threads_running,pct:innodb_buffer_pool_pages_dirty/innodb_buffer_pool_pages_total'.
+// Variables can be regexes, so the columns are only built once the first state shows which variables exist.
+type ColsView struct {
+	NormalView
+	specs []colSpec
+}
+
+// Views that can only build their columns from a state
+type expandingView interface {
+	expand(state *MyqState)
+}
+
+// Check the spec and return a view for it.  Kinds are gauge, rate, diff, pct (numerator/denominator), string and expr (see ExprCol).
+func NewColsView(spec string) (*ColsView, error) {
+	var specs []colSpec
+	for _, item := range strings.Split(spec, `,`) {
+		parts := strings.SplitN(strings.TrimSpace(item), `:`, 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("'%s' should look like kind:variable", item)
+		}
+		cs := colSpec{parts[0], lowerVariables(parts[1])}
+
+		switch cs.kind {
+		case `gauge`, `rate`, `diff`, `string`:
+		case `expr`:
+			if _, err := parseExpr(cs.variable); err != nil {
+				return nil, err
+			}
+			specs = append(specs, cs)
+			continue // not a regex
+		case `pct`:
+			if len(strings.Split(cs.variable, `/`)) != 2 {
+				return nil, fmt.Errorf("'%s' should look like pct:numerator/denominator", item)
+			}
+		default:
+			return nil, fmt.Errorf("'%s' has an unknown kind, use gauge, rate, diff, pct, string or expr", item)
+		}
+		if _, err := regexp.Compile(cs.variable); err != nil {
+			return nil, fmt.Errorf("'%s': %s", item, err)
+		}
+		specs = append(specs, cs)
+	}
+
+	return &ColsView{NormalView{DefaultCol: DefaultCol{help: fmt.Sprint(`Ad-hoc view of `, spec)}}, specs}, nil
+}
+
+// Status variables are stored lowercase, but server variables keep the uppercase VAR_PREFIX
+func lowerVariables(spec string) string {
+	parts := strings.Split(spec, VAR_PREFIX)
+	for i, part := range parts {
+		parts[i] = strings.ToLower(part)
+	}
+	return strings.Join(parts, VAR_PREFIX)
+}
+
+// Variable names (exact, or every match of a regex) for a spec, in a stable order
+func (cs colSpec) variables(sample MyqSample) []string {
+	if regexp.QuoteMeta(cs.variable) == cs.variable {
+		return []string{cs.variable} // not a regex, so don't match other variables that contain it
+	}
+	expanded := expand_variables([]string{cs.variable}, sample)
+	sort.Strings(expanded)
+	return expanded
+}
+
+// Width to fit the name in the header
+func adhocWidth(name string) int64 {
+	if width := int64(len(name)); width > MIN_ADHOC_WIDTH {
+		return width
+	}
+	return MIN_ADHOC_WIDTH
+}
+
+// Build the columns, but only once
+func (v *ColsView) expand(state *MyqState) {
+	if v.cols != nil {
+		return
+	}
+	for _, cs := range v.specs {
+		if cs.kind == `pct` {
+			vars := strings.Split(cs.variable, `/`)
+			name := fmt.Sprint(`%`, vars[0])
+			v.cols = append(v.cols, NewPercentCol(name, cs.variable, adhocWidth(name), vars[0], vars[1], 0))
+			continue
+		} else if cs.kind == `expr` {
+			col, _ := NewExprCol(cs.variable, cs.variable, adhocWidth(cs.variable), cs.variable, 2, NumberUnits) // checked already
+			v.cols = append(v.cols, col)
+			continue
+		}
+
+		for _, variable := range cs.variables(state.Cur) {
+			width := adhocWidth(variable)
+			switch cs.kind {
+			case `gauge`:
+				v.cols = append(v.cols, NewGaugeCol(variable, variable, width, variable, 0, NumberUnits))
+			case `rate`:
+				v.cols = append(v.cols, NewRateCol(variable, fmt.Sprint(variable, ` / s`), width, variable, 0, NumberUnits))
+			case `diff`:
+				v.cols = append(v.cols, NewDiffCol(variable, fmt.Sprint(variable, ` since the last sample`), width, variable, 0, NumberUnits))
+			case `string`:
+				v.cols = append(v.cols, NewStringCol(variable, variable, width, variable))
+			}
+		}
+	}
+	// Nothing matched, but we still need something to show
+	if v.cols == nil {
+		v.cols = []Col{}
+	}
+}
+
+func (v *ColsView) Header(state *MyqState) chan string {
+	v.expand(state)
+	return v.NormalView.Header(state)
+}
+
+func (v *ColsView) Data(state *MyqState) chan string {
+	v.expand(state)
+	return v.NormalView.Data(state)
+}
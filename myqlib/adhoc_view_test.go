@@ -0,0 +1,82 @@
+package myqlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColsView(t *testing.T) {
+	v, err := NewColsView(`rate:com_select,gauge:threads_running,pct:innodb_buffer_pool_pages_dirty/innodb_buffer_pool_pages_total,diff:^com_(insert|update)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetTimeCol(&Runtime_col)
+
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-states
+	state := <-states
+
+	var headers []string
+	for header := range v.Header(state) {
+		headers = append(headers, header)
+	}
+	if headers[0] != `    time com_select threads_running %innodb_buffer_pool_pages_dirty com_insert com_update` {
+		t.Errorf("Unexpected header: '%s'", headers[0])
+	}
+	for data := range v.Data(state) {
+		if data != `      1s       1398               4                              1%         99         94` {
+			t.Errorf("Unexpected data: '%s'", data)
+		}
+	}
+
+	// Machine readable output expands the columns too
+	v, _ = NewColsView(`gauge:threads_run.*`)
+	var buf bytes.Buffer
+	WriteJSON(&buf, v, state)
+	if buf.String() != "{\"threads_running\":4}\n" {
+		t.Error("Unexpected JSON:", buf.String())
+	}
+}
+
+func TestColsViewServerVariables(t *testing.T) {
+	v, err := NewColsView(`gauge:V_max_connections,pct:Max_used_connections/V_max_connections`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetTimeCol(&Runtime_col)
+
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "../testdata/variables")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := <-states
+
+	var buf bytes.Buffer
+	WriteJSON(&buf, v, state)
+	if !strings.HasPrefix(buf.String(), "{\"time\":0,\"V_max_connections\":151,\"%max_used_connections\":84.7") {
+		t.Error("Unexpected JSON:", buf.String())
+	}
+}
+
+func TestBadColsView(t *testing.T) {
+	for spec, expected := range map[string]string{
+		`com_select`:           `should look like kind:variable`,
+		`rate:`:                `should look like kind:variable`,
+		`sum:com_select`:       `unknown kind`,
+		`expr:rate(com_select`: `missing ')'`,
+		`pct:com_select`:       `should look like pct:numerator/denominator`,
+		`gauge:threads_(`:      `missing closing )`,
+		`rate:a,gauge:b,,`:     `should look like kind:variable`,
+	} {
+		if _, err := NewColsView(spec); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected '%s' for '%s', got %v", expected, spec, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package myqlib
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Nagios plugin exit codes (the first three match the THRESHOLD_ levels)
+const (
+	CHECK_OK int = iota
+	CHECK_WARNING
+	CHECK_CRITICAL
+	CHECK_UNKNOWN
+)
+
+var checkStatusNames = []string{`OK`, `WARNING`, `CRITICAL`, `UNKNOWN`}
+
+// Prefix of the status line
+const CHECK_SERVICE string = "MYQ"
+
+// One column of a view to check against its thresholds
+type check struct {
+	label string // view.col or view.group.col, as given
+	col   ThresholdCol
+	units string // perfdata UOM
+	sum   float64
+	count int
+}
+
+// Checks the average value of view columns across samples against thresholds.
+type Checker struct {
+	checks  []*check
+	samples int
+}
+
+// Parse checks like 'innodb.Hist>500000,cttf.run>32:64,innodb.Buffer Pool.dirt'.
+// A column is named by its view, then its group (if it needs it to be unique) and name.
+// One number is the critical threshold, 'warn:crit' gives both, and no condition
+// uses the column's own warn and crit (from its view).
+func NewChecker(spec string, views map[string]View) (*Checker, error) {
+	c := &Checker{}
+	for _, item := range strings.Split(spec, `,`) {
+		item = strings.TrimSpace(item)
+		path, cond := item, ""
+		if i := strings.IndexAny(item, `<>`); i >= 0 {
+			path, cond = strings.TrimSpace(item[:i]), item[i:]
+		}
+
+		parts := strings.SplitN(path, `.`, 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("'%s' should look like view.col>threshold", item)
+		}
+		v, ok := views[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("'%s': view %s not found", item, parts[0])
+		}
+		col, err := find_col(v, parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %s", item, err)
+		}
+
+		ch := &check{label: path}
+		tc, hasThresholds := col.(ThresholdCol)
+		if hasThresholds {
+			col = tc.ValueCol
+		}
+		if _, ok := col.(PercentCol); ok {
+			ch.units = `%`
+		}
+		if cond == "" {
+			if !hasThresholds {
+				return nil, fmt.Errorf("'%s': the column has no thresholds, give one like %s>100", item, path)
+			}
+			ch.col = tc
+			c.checks = append(c.checks, ch)
+			continue
+		}
+
+		// Same comparison for both, so '>' isn't repeated after the ':'
+		op := cond[:1]
+		if strings.HasPrefix(cond[1:], `=`) {
+			op = cond[:2]
+		}
+		limits := strings.SplitN(cond[len(op):], `:`, 2)
+		warn, crit := "", fmt.Sprint(op, limits[0])
+		if len(limits) == 2 {
+			warn, crit = fmt.Sprint(op, limits[0]), fmt.Sprint(op, limits[1])
+		}
+		if ch.col, err = NewThresholdCol(col, warn, crit); err != nil {
+			return nil, fmt.Errorf("'%s': %s", item, err)
+		}
+		c.checks = append(c.checks, ch)
+	}
+	return c, nil
+}
+
+// Find a column in a view by 'name' or 'group.name'
+func find_col(v View, path string) (ValueCol, error) {
+	var found []ValueCol
+	var walk func(cols []Col, group string)
+	walk = func(cols []Col, group string) {
+		for _, col := range cols {
+			switch c := col.(type) {
+			case *GroupCol:
+				walk(c.all_cols(), c.title)
+			case ValueCol:
+				if c.Name() == path || fmt.Sprint(group, `.`, c.Name()) == path {
+					found = append(found, c)
+				}
+			}
+		}
+	}
+	walk(v.all_cols(), "")
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no column %s", path)
+	} else if len(found) > 1 {
+		return nil, fmt.Errorf("%d columns are called %s, use group.col", len(found), path)
+	}
+	return found[0], nil
+}
+
+// Include a sample in the averages.  The first state is only a baseline
+// for rates, so it's skipped.
+func (c *Checker) Add(state *MyqState) {
+	if state.Prev == nil {
+		return
+	}
+	c.samples++
+	for _, ch := range c.checks {
+		if val, ok := ch.col.Value(state).(float64); ok && finite(val) {
+			ch.sum += val
+			ch.count++
+		}
+	}
+}
+
+// How many samples have been checked
+func (c *Checker) Samples() int {
+	return c.samples
+}
+
+// The worst status of all the checks and a Nagios status line with perfdata for each
+func (c *Checker) Result() (int, string) {
+	status := CHECK_OK
+	var problems []string
+	var perfdata bytes.Buffer
+	for _, ch := range c.checks {
+		if ch.count == 0 {
+			status = worse(status, CHECK_UNKNOWN)
+			problems = append(problems, fmt.Sprint(ch.label, ` has no value`))
+			continue
+		}
+		val := ch.sum / float64(ch.count)
+		formatted := strconv.FormatFloat(math.Round(val*1000)/1000, 'f', -1, 64)
+
+		switch level := ch.col.level(val); level {
+		case THRESHOLD_CRIT:
+			status = worse(status, CHECK_CRITICAL)
+			problems = append(problems, fmt.Sprint(ch.label, `=`, formatted, ch.units, ` (`, ch.col.crit, `)`))
+		case THRESHOLD_WARN:
+			status = worse(status, CHECK_WARNING)
+			problems = append(problems, fmt.Sprint(ch.label, `=`, formatted, ch.units, ` (`, ch.col.warn, `)`))
+		}
+
+		fmt.Fprintf(&perfdata, ` '%s'=%s%s;%s;%s`, ch.label, formatted, ch.units, nagiosRange(ch.col.warn), nagiosRange(ch.col.crit))
+	}
+
+	summary := fmt.Sprint(`all checks ok over `, c.samples, ` samples`)
+	if len(problems) > 0 {
+		summary = strings.Join(problems, `, `)
+	}
+	return status, fmt.Sprint(CHECK_SERVICE, ` `, checkStatusNames[status], ` - `, summary, ` |`, perfdata.String())
+}
+
+// The worse of two statuses, UNKNOWN is worse than WARNING but not CRITICAL
+func worse(a, b int) int {
+	rank := []int{CHECK_OK: 0, CHECK_WARNING: 1, CHECK_UNKNOWN: 2, CHECK_CRITICAL: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// Nagios ranges alert outside of them, so '>10' is '10' and '<10' is '10:'
+func nagiosRange(c *Condition) string {
+	if c == nil {
+		return ""
+	}
+	value := strconv.FormatFloat(c.value, 'f', -1, 64)
+	if strings.HasPrefix(c.op, `<`) {
+		return fmt.Sprint(value, `:`)
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package myqlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func runChecker(t *testing.T, spec string) (int, string) {
+	c, err := NewChecker(spec, DefaultViews())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.byfives", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state := range states {
+		if c.Samples() == 3 {
+			break
+		}
+		c.Add(state)
+	}
+	return c.Result()
+}
+
+func TestChecker(t *testing.T) {
+	status, line := runChecker(t, `innodb.Hist>500000,cttf.run>64`)
+	if status != CHECK_OK || line != `MYQ OK - all checks ok over 3 samples | 'innodb.Hist'=0;;500000 'cttf.run'=1;;64` {
+		t.Error("Unexpected result:", status, line)
+	}
+
+	// The column's own thresholds, and warn:crit
+	status, line = runChecker(t, `innodb.Buffer Pool.dirt,cttf.Threads.conn>=1:2`)
+	if status != CHECK_WARNING || line != `MYQ WARNING - cttf.Threads.conn=1 (>=1) | 'innodb.Buffer Pool.dirt'=0%;75;90 'cttf.Threads.conn'=1;1;2` {
+		t.Error("Unexpected result:", status, line)
+	}
+
+	status, line = runChecker(t, `cttf.run>0.5:0.9,coms.sel<10`)
+	if status != CHECK_CRITICAL || !strings.HasPrefix(line, `MYQ CRITICAL - cttf.run=1 (>0.9), coms.sel=0 (<10) |`) {
+		t.Error("Unexpected result:", status, line)
+	}
+	if !strings.HasSuffix(line, `'coms.sel'=0;;10:`) {
+		t.Error("Expected a Nagios range for <:", line)
+	}
+}
+
+func TestCheckerUnknown(t *testing.T) {
+	// No value for the column is unknown, but critical is worse
+	status, line := runChecker(t, `wsrep.Cluster.#>0.1`)
+	if status != CHECK_UNKNOWN || !strings.HasPrefix(line, `MYQ UNKNOWN - wsrep.Cluster.# has no value |`) {
+		t.Error("Unexpected result:", status, line)
+	}
+	if status, _ := runChecker(t, `wsrep.Cluster.#>0.1,cttf.run>0`); status != CHECK_CRITICAL {
+		t.Error("Expected critical, got", status)
+	}
+
+	if c, _ := NewChecker(`cttf.run>1`, DefaultViews()); c.Samples() != 0 {
+		t.Error("Expected no samples yet")
+	}
+	if status, line := (&Checker{}).Result(); status != CHECK_OK || !strings.HasPrefix(line, `MYQ OK`) {
+		t.Error("Unexpected result with no checks:", status, line)
+	}
+}
+
+func TestBadChecks(t *testing.T) {
+	for _, spec := range []string{`cttf`, `nope.run>1`, `innodb.read>1`, `innodb.nope>1`, `cttf.cons`, `cttf.run>lots`, `cttf.run>1,`} {
+		if _, err := NewChecker(spec, DefaultViews()); err == nil {
+			t.Error("Expected an error for", spec)
+		}
+	}
+}
@@ -17,6 +17,17 @@ type Col interface {
 
 	// width of the column
 	Width() int64
+
+	// name of the column (used in the header)
+	Name() string
+}
+
+// Columns that can also give their value without any formatting (for machine readable output)
+type ValueCol interface {
+	Col
+
+	// The unrounded value: a float64, a string (or a time.Time), or nil if there isn't one
+	Value(state *MyqState) interface{}
 }
 
 // 'Default' column -- "inherited" by others
@@ -33,6 +44,7 @@ func (c DefaultCol) Help() chan string {
 	return ch
 }
 func (c DefaultCol) Width() int64 { return c.width }
+func (c DefaultCol) Name() string { return c.name }
 
 func (c DefaultCol) Header(state *MyqState) chan string {
 	ch := make(chan string, 1)
@@ -64,15 +76,25 @@ func NewGaugeCol(name, help string, width int64, variable_name string, precision
 	return GaugeCol{DefaultCol{name, help, width}, NumCol{precision, units}, variable_name}
 }
 
+func (c GaugeCol) Value(state *MyqState) interface{} {
+	if val, err := state.Cur.getFloat(c.variable_name); err == nil {
+		return val
+	} else if val, err := state.Cur.getString(c.variable_name); err == nil {
+		return val
+	}
+	return nil
+}
+
 func (c GaugeCol) Data(state *MyqState) chan string {
 	ch := make(chan string, 1)
 	defer close(ch)
 
-	if val, err := state.Cur.getFloat(c.variable_name); err == nil {
+	switch val := c.Value(state).(type) {
+	case float64:
 		ch <- fit_string(collapse_number(val, c.Width(), c.precision, c.units), c.Width())
-	} else if val, err := state.Cur.getString(c.variable_name); err == nil {
+	case string:
 		ch <- fit_string(val, c.Width())
-	} else {
+	default:
 		// must be missing, just filler
 		ch <- column_filler(c)
 	}
@@ -80,6 +102,11 @@ func (c GaugeCol) Data(state *MyqState) chan string {
 	return ch
 }
 
+// Rates and differences need a previous sample, which the first state and those after a gap don't have
+func no_prev(state *MyqState) bool {
+	return state.Gap || state.Prev == nil
+}
+
 // Rate Columns the rate of change of a SHOW STATUS variable
 type RateCol struct {
 	GaugeCol
@@ -89,19 +116,29 @@ func NewRateCol(name, help string, width int64, variable_name string, precision
 	return RateCol{GaugeCol{DefaultCol{name, help, width}, NumCol{precision, units}, variable_name}}
 }
 
+func (c RateCol) Value(state *MyqState) interface{} {
+	cnum, cerr := state.Cur.getFloat(c.variable_name)
+	pnum, _ := state.Prev.getFloat(c.variable_name)
+
+	if cerr != nil || no_prev(state) { // we only care about cerr, if perr is set, it should be a 0.0
+		return nil
+	}
+	return calculate_rate(cnum, pnum, state.SecondsDiff)
+}
+
 func (c RateCol) Data(state *MyqState) chan string {
 	ch := make(chan string, 1)
 	defer close(ch)
 
-	cnum, cerr := state.Cur.getFloat(c.variable_name)
-	pnum, _ := state.Prev.getFloat(c.variable_name)
-
-	if cerr != nil { // we only care about cerr, if perr is set, it should be a 0.0
-		ch <- column_filler(c)
-	} else {
-		cv := collapse_number(calculate_rate(cnum, pnum, state.SecondsDiff),
-			c.Width(), c.precision, c.units)
+	if rate, ok := c.Value(state).(float64); ok {
+		cv := collapse_number(rate, c.Width(), c.precision, c.units)
 		ch <- fit_string(cv, c.Width())
+	} else if cnum, err := state.Cur.getFloat(c.variable_name); err == nil && state.Gap { // nothing to compare against
+		ch <- column_gap(c)
+	} else if err == nil && state.Prev == nil { // the first row shows the totals so far
+		ch <- fit_string(collapse_number(cnum, c.Width(), c.precision, c.units), c.Width())
+	} else {
+		ch <- column_filler(c)
 	}
 	return ch
 }
@@ -115,19 +152,29 @@ func NewDiffCol(name, help string, width int64, variable_name string, precision
 	return DiffCol{GaugeCol{DefaultCol{name, help, width}, NumCol{precision, units}, variable_name}}
 }
 
+func (c DiffCol) Value(state *MyqState) interface{} {
+	cnum, cerr := state.Cur.getFloat(c.variable_name)
+	pnum, _ := state.Prev.getFloat(c.variable_name)
+
+	if cerr != nil || no_prev(state) { // we only care about cerr, if perr is set, it should be a 0.0
+		return nil
+	}
+	return calculate_diff(cnum, pnum)
+}
+
 func (c DiffCol) Data(state *MyqState) chan string {
 	ch := make(chan string, 1)
 	defer close(ch)
 
-	cnum, cerr := state.Cur.getFloat(c.variable_name)
-	pnum, _ := state.Prev.getFloat(c.variable_name)
-
-	if cerr != nil { // we only care about cerr, if perr is set, it should be a 0.0
-		ch <- column_filler(c)
-	} else {
-		cv := collapse_number(
-			calculate_diff(cnum, pnum), c.Width(), c.precision, c.units)
+	if diff, ok := c.Value(state).(float64); ok {
+		cv := collapse_number(diff, c.Width(), c.precision, c.units)
 		ch <- fit_string(cv, c.Width())
+	} else if cnum, err := state.Cur.getFloat(c.variable_name); err == nil && state.Gap { // nothing to compare against
+		ch <- column_gap(c)
+	} else if err == nil && state.Prev == nil { // the first row shows the totals so far
+		ch <- fit_string(collapse_number(cnum, c.Width(), c.precision, c.units), c.Width())
+	} else {
+		ch <- column_filler(c)
 	}
 	return ch
 }
@@ -135,14 +182,26 @@ func (c DiffCol) Data(state *MyqState) chan string {
 // Func Columns run a custom function to produce their output
 type FuncCol struct {
 	DefaultCol
-	fn func(state *MyqState, c Col) chan string // takes the state and returns the (unformatted) value
+	fn    func(state *MyqState, c Col) chan string // takes the state and returns the (unformatted) value
+	value func(state *MyqState) interface{}        // optional raw value of the column
 }
 
 func (c FuncCol) Data(state *MyqState) chan string {
 	return c.fn(state, c)
 }
+func (c FuncCol) Value(state *MyqState) interface{} {
+	if c.value == nil {
+		return nil
+	}
+	return c.value(state)
+}
 func NewFuncCol(name, help string, width int64, fn func(*MyqState, Col) chan string) FuncCol {
-	return FuncCol{DefaultCol{name, help, width}, fn}
+	return FuncCol{DefaultCol{name, help, width}, fn, nil}
+}
+
+// A FuncCol that can also give its raw value
+func NewFuncValueCol(name, help string, width int64, fn func(*MyqState, Col) chan string, value func(*MyqState) interface{}) FuncCol {
+	return FuncCol{DefaultCol{name, help, width}, fn, value}
 }
 
 // Percent Columns calculate a ratio between two metrics
@@ -156,19 +215,26 @@ func NewPercentCol(name, help string, w int64, numerator, denomenator string, p
 	return PercentCol{DefaultCol{name, help, w}, NumCol{p, PercentUnits}, numerator, denomenator}
 }
 
-func (c PercentCol) Data(state *MyqState) chan string {
-	ch := make(chan string, 1)
-	defer close(ch)
-
+func (c PercentCol) Value(state *MyqState) interface{} {
 	numerator, nerr := state.Cur.getFloat(c.numerator)
 	denomenator, derr := state.Cur.getFloat(c.denomenator)
 
 	// Must have both
 	if nerr != nil || derr != nil || denomenator == 0 {
-		ch <- column_filler(c)
-	} else {
-		cv := collapse_number((numerator/denomenator)*100, c.Width(), c.precision, c.units)
+		return nil
+	}
+	return (numerator / denomenator) * 100
+}
+
+func (c PercentCol) Data(state *MyqState) chan string {
+	ch := make(chan string, 1)
+	defer close(ch)
+
+	if pct, ok := c.Value(state).(float64); ok {
+		cv := collapse_number(pct, c.Width(), c.precision, c.units)
 		ch <- fit_string(cv, c.Width())
+	} else {
+		ch <- column_filler(c)
 	}
 	return ch
 }
@@ -183,6 +249,13 @@ func NewStringCol(name, help string, w int64, variable_name string) StringCol {
 	return StringCol{DefaultCol{name, help, w}, variable_name}
 }
 
+func (c StringCol) Value(state *MyqState) interface{} {
+	if val, err := state.Cur.getString(c.variable_name); err == nil {
+		return val
+	}
+	return nil
+}
+
 func (c StringCol) Data(state *MyqState) chan string {
 	ch := make(chan string, 1)
 	defer close(ch)
@@ -218,14 +291,18 @@ func NewCurDiffCol(name, help string, width int64, bigger, smaller string, preci
 	return CurDiffCol{DefaultCol{name, help, width}, NumCol{precision, units}, bigger, smaller}
 }
 
+func (c CurDiffCol) Value(state *MyqState) interface{} {
+	bnum, _ := state.Cur.getFloat(c.bigger)
+	snum, _ := state.Cur.getFloat(c.smaller)
+
+	return calculate_diff(bnum, snum)
+}
+
 func (c CurDiffCol) Data(state *MyqState) chan string {
 	ch := make(chan string, 1)
 	defer close(ch)
 
-	bnum, _ := state.Cur.getFloat(c.bigger)
-	snum, _ := state.Cur.getFloat(c.smaller)
-
-	cv := collapse_number(calculate_diff(bnum, snum), c.Width(), c.precision, c.units)
+	cv := collapse_number(c.Value(state).(float64), c.Width(), c.precision, c.units)
 	ch <- fit_string(cv, c.Width())
 	return ch
 }
@@ -242,16 +319,33 @@ func NewRateSumCol(name, help string, width int64, precision int64, units UnitsD
 	return RateSumCol{DefaultCol{name, help, width}, NumCol{precision, units}, variables, []string{}}
 }
 
-func (c RateSumCol) Data(state *MyqState) chan string {
-	ch := make(chan string, 1)
-	defer close(ch)
+func (c RateSumCol) Value(state *MyqState) interface{} {
+	if no_prev(state) { // nothing to compare against
+		return nil
+	}
 
 	c.expand_variables(state.Cur)
 
 	cursum := calculate_sum(state.Cur, c.expanded_variable_names)
 	prevsum := calculate_sum(state.Prev, c.expanded_variable_names)
 
-	rate := calculate_rate(cursum, prevsum, state.SecondsDiff)
+	return calculate_rate(cursum, prevsum, state.SecondsDiff)
+}
+
+func (c RateSumCol) Data(state *MyqState) chan string {
+	ch := make(chan string, 1)
+	defer close(ch)
+
+	if state.Gap { // nothing to compare against
+		ch <- column_gap(c)
+		return ch
+	}
+
+	rate, ok := c.Value(state).(float64)
+	if !ok { // the first row shows the totals so far
+		c.expand_variables(state.Cur)
+		rate = calculate_sum(state.Cur, c.expanded_variable_names)
+	}
 	cv := collapse_number(rate, c.Width(), c.precision, c.units)
 	ch <- fit_string(cv, c.Width())
 
@@ -263,3 +357,61 @@ func (c *RateSumCol) expand_variables(sample MyqSample) {
 		c.expanded_variable_names = expand_variables(c.variable_names, sample)
 	}
 }
+
+// Top Columns show a line for each of the rows (like statement digests) with the biggest differences since the last sample
+type TopCol struct {
+	DefaultCol
+	NumCol
+	rows  func(state *MyqState) []string               // the ids of the rows to show, in order
+	value func(state *MyqState, id string) interface{} // float64, string or nil
+}
+
+func NewTopCol(name, help string, width int64, precision int64, units UnitsDef, rows func(*MyqState) []string, value func(*MyqState, string) interface{}) TopCol {
+	return TopCol{DefaultCol{name, help, width}, NumCol{precision, units}, rows, value}
+}
+
+// The value for the top row
+func (c TopCol) Value(state *MyqState) interface{} {
+	if no_prev(state) {
+		return nil
+	}
+	if rows := c.rows(state); len(rows) > 0 {
+		return c.value(state, rows[0])
+	}
+	return nil
+}
+
+func (c TopCol) Data(state *MyqState) chan string {
+	if no_prev(state) { // nothing to compare against
+		ch := make(chan string, 1)
+		defer close(ch)
+		if state.Gap {
+			ch <- column_gap(c)
+		} else {
+			ch <- column_filler(c)
+		}
+		return ch
+	}
+
+	rows := c.rows(state)
+	ch := make(chan string, len(rows)+1)
+	defer close(ch)
+
+	// Nothing happened
+	if len(rows) == 0 {
+		ch <- column_filler(c)
+		return ch
+	}
+
+	for _, id := range rows {
+		switch val := c.value(state, id).(type) {
+		case float64:
+			ch <- fit_string(collapse_number(val, c.Width(), c.precision, c.units), c.Width())
+		case string:
+			ch <- fit_string(val, c.Width())
+		default:
+			ch <- column_filler(c)
+		}
+	}
+	return ch
+}
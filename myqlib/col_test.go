@@ -76,6 +76,22 @@ func TestRateCol(t *testing.T) {
 	b.Reset()
 }
 
+func TestGapRateCol(t *testing.T) {
+	col := NewRateCol("cons", "Connections per second", 5, "connections", 0, NumberUnits)
+
+	state := MyqState{Gap: true}
+	state.Cur = make(MyqSample)
+	state.Cur["connections"] = "10"
+	state.Prev = make(MyqSample)
+	state.Prev["connections"] = "20000"
+	state.SecondsDiff = 1.0
+
+	str := <-col.Data(&state)
+	if str != "  gap" {
+		t.Fatal("Bad output", str, `.`)
+	}
+}
+
 // implement large number collapsing first
 // state.Cur["threads_running"] = "100000"
 // col.Data( &b, state )
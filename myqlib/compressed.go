@@ -0,0 +1,76 @@
+package myqlib
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"os"
+)
+
+// Name that means read from stdin instead of a file
+const STDIN_FILE string = "-"
+
+// Magic bytes at the start of compressed files
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte(`BZh`)
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// A decompressing reader that closes everything underneath it
+type captureReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *captureReader) Close() (err error) {
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if cerr := r.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+// Open a capture file (or stdin for STDIN_FILE), decompressing it if it looks compressed
+func openCapture(filename string) (io.ReadCloser, error) {
+	var file *os.File
+	if filename == STDIN_FILE {
+		file = os.Stdin
+	} else {
+		var err error
+		if file, err = os.OpenFile(filename, os.O_RDONLY, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	// Peek at the magic bytes, short files are fine (they can't be compressed)
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(zstdMagic))
+	r := &captureReader{buffered, []io.Closer{file}}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		r.Reader, r.closers = gz, append(r.closers, gz)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		r.Reader = bzip2.NewReader(buffered)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		zrc := zr.IOReadCloser()
+		r.Reader, r.closers = zrc, append(r.closers, zrc)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,64 @@
+package myqlib
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Statement digests, cumulative since the server started (or the table was truncated)
+	DIGEST_QUERY string = "SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, COUNT_STAR, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ROWS_SENT FROM performance_schema.events_statements_summary_by_digest"
+
+	// prefix of digest keys in a sample: digest_<field>.<schema>.<digest>, or digest_<field>.<digest> without a schema
+	DIGEST_PREFIX = "digest_"
+
+	// How many digests the digests view shows each sample
+	DIGEST_TOP_N = 10
+)
+
+// performance_schema statement digests for live loaders to collect too
+var DigestsQuery = ExtraQuery{query: DIGEST_QUERY, add: addDigest}
+
+// The key of a digest field in a sample
+func digestKey(field, id string) string {
+	return summaryKey(DIGEST_PREFIX, field, id)
+}
+
+// Whether a vertical row is a statement digest
+func isDigestRow(row map[string]string) bool {
+	fields := map[string]bool{}
+	for name := range row {
+		fields[strings.ToLower(name)] = true
+	}
+	return fields[`digest`] && fields[`count_star`]
+}
+
+// Add one row of events_statements_summary_by_digest to a sample
+func addDigest(sample MyqSample, row map[string]string) {
+	fields := map[string]string{}
+	for name, value := range row {
+		fields[strings.ToLower(name)] = value
+	}
+	id := fields[`digest`]
+	if id == "" {
+		return // statements after the table filled up (performance_schema_digests_size)
+	}
+	if schema := fields[`schema_name`]; schema != "" {
+		id = fmt.Sprint(schema, `.`, id) // the same statement in another schema is another digest
+	}
+	addSummary(sample, DIGEST_PREFIX, id, fields)
+}
+
+// The digests view's columns
+func digest_cols() []Col {
+	top := top_summaries(DIGEST_PREFIX, DIGEST_TOP_N)
+	return []Col{
+		NewTopCol(`cnt`, `Statements since the last sample`, 5, 0, NumberUnits, top, summary_diff(DIGEST_PREFIX, `count_star`)),
+		NewTopCol(`lat`, `Total latency since the last sample`, 5, 0, SecondUnits, top, summary_seconds(DIGEST_PREFIX, `sum_timer_wait`)),
+		NewTopCol(`avg`, `Average latency since the last sample`, 5, 0, SecondUnits, top, summary_avg(DIGEST_PREFIX)),
+		NewTopCol(`exam`, `Rows examined since the last sample`, 5, 0, NumberUnits, top, summary_diff(DIGEST_PREFIX, `sum_rows_examined`)),
+		NewTopCol(`sent`, `Rows sent since the last sample`, 5, 0, NumberUnits, top, summary_diff(DIGEST_PREFIX, `sum_rows_sent`)),
+		NewTopCol(`schema`, `Default schema`, 10, 0, NumberUnits, top, summary_string(DIGEST_PREFIX, `schema_name`)),
+		NewTopCol(`digest`, `Normalized statement`, 60, 0, NumberUnits, top, summary_string(DIGEST_PREFIX, `digest_text`)),
+	}
+}
@@ -0,0 +1,113 @@
+package myqlib
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Two samples of digests from a live server in vertical output, before the status
+var digestOutputs = []string{`*************************** 1. row ***************************
+      SCHEMA_NAME: shop
+           DIGEST: 6e1e9b0c
+      DIGEST_TEXT: SELECT * FROM orders WHERE id = ?
+       COUNT_STAR: 1000
+   SUM_TIMER_WAIT: 2000000000000
+SUM_ROWS_EXAMINED: 1000
+    SUM_ROWS_SENT: 1000
+*************************** 2. row ***************************
+      SCHEMA_NAME: NULL
+           DIGEST: 9f3a1d22
+      DIGEST_TEXT: SHOW GLOBAL STATUS
+       COUNT_STAR: 10
+   SUM_TIMER_WAIT: 50000000000
+SUM_ROWS_EXAMINED: 4000
+    SUM_ROWS_SENT: 4000
+Uptime	100
+`, `*************************** 1. row ***************************
+      SCHEMA_NAME: shop
+           DIGEST: 6e1e9b0c
+      DIGEST_TEXT: SELECT * FROM orders WHERE id = ?
+       COUNT_STAR: 1400
+   SUM_TIMER_WAIT: 2600000000000
+SUM_ROWS_EXAMINED: 1400
+    SUM_ROWS_SENT: 1400
+*************************** 2. row ***************************
+      SCHEMA_NAME: NULL
+           DIGEST: 9f3a1d22
+      DIGEST_TEXT: SHOW GLOBAL STATUS
+       COUNT_STAR: 11
+   SUM_TIMER_WAIT: 55000000000
+SUM_ROWS_EXAMINED: 4400
+    SUM_ROWS_SENT: 4400
+*************************** 3. row ***************************
+      SCHEMA_NAME: dw
+           DIGEST: 0b7c44e1
+      DIGEST_TEXT: SELECT COUNT ( * )   FROM events
+       COUNT_STAR: 1
+   SUM_TIMER_WAIT: 3000000000000
+SUM_ROWS_EXAMINED: 2000000
+    SUM_ROWS_SENT: 1
+Uptime	101
+`}
+
+func TestDigests(t *testing.T) {
+	prev, cur := summarySamples(t, digestOutputs)
+	if got := cur.getStr(digestKey(`count_star`, `shop.6e1e9b0c`)); got != `1400` {
+		t.Error("Unexpected count:", got)
+	}
+	if got := cur.getStr(digestKey(`schema_name`, `9f3a1d22`)); got != `` {
+		t.Error("Expected a NULL schema to be empty:", got)
+	}
+	if got := cur.getStr(`repl_digest`); got != `` {
+		t.Error("Digests leaked into replication status:", got)
+	}
+
+	state := &MyqState{Cur: cur, Prev: prev, SecondsDiff: 1}
+	if ids := top_summaries(DIGEST_PREFIX, 10)(state); !reflect.DeepEqual(ids, []string{`dw.0b7c44e1`, `shop.6e1e9b0c`, `9f3a1d22`}) {
+		t.Error("Unexpected top digests:", ids)
+	}
+	if ids := top_summaries(DIGEST_PREFIX, 1)(state); !reflect.DeepEqual(ids, []string{`dw.0b7c44e1`}) {
+		t.Error("Expected just the slowest digest:", ids)
+	}
+
+	if avg := summary_avg(DIGEST_PREFIX)(state, `shop.6e1e9b0c`); avg != 0.0015 {
+		t.Error("Unexpected average latency:", avg)
+	}
+	if exam := summary_diff(DIGEST_PREFIX, `sum_rows_examined`)(state, `9f3a1d22`); exam != 400.0 {
+		t.Error("Unexpected rows examined:", exam)
+	}
+	if text := summary_string(DIGEST_PREFIX, `digest_text`)(state, `dw.0b7c44e1`); text != `SELECT COUNT ( * ) FROM events` {
+		t.Errorf("Expected the statement on one line: %q", text)
+	}
+
+	// Nothing ran
+	if ids := top_summaries(DIGEST_PREFIX, 10)(&MyqState{Cur: cur, Prev: cur, SecondsDiff: 1}); len(ids) != 0 {
+		t.Error("Expected no digests:", ids)
+	}
+}
+
+func TestDigestsTruncated(t *testing.T) {
+	prev, cur := summarySamples(t, digestOutputs)
+
+	// performance_schema_digests_size filled up and someone truncated the table
+	truncated := MyqSample{}
+	for key, value := range cur {
+		truncated[key] = value
+	}
+	truncated[digestKey(`count_star`, `shop.6e1e9b0c`)] = `20`
+	truncated[digestKey(`sum_timer_wait`, `shop.6e1e9b0c`)] = `40000000000`
+	state := &MyqState{Cur: truncated, Prev: prev, SecondsDiff: 1}
+
+	// Not the totals since the truncate as if they were since the last sample
+	for _, field := range []string{`count_star`, `sum_timer_wait`, `sum_rows_examined`} {
+		if diff := summary_diff(DIGEST_PREFIX, field)(state, `shop.6e1e9b0c`); diff != nil {
+			t.Error("Expected no", field, "after a truncate:", diff)
+		}
+	}
+	if avg := summary_avg(DIGEST_PREFIX)(state, `shop.6e1e9b0c`); avg != nil {
+		t.Error("Expected no average after a truncate:", avg)
+	}
+	if ids := top_summaries(DIGEST_PREFIX, 10)(state); !reflect.DeepEqual(ids, []string{`dw.0b7c44e1`, `9f3a1d22`}) {
+		t.Error("Unexpected top digests after a truncate:", ids)
+	}
+}
@@ -0,0 +1,109 @@
+package myqlib
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Content type of the Prometheus text exposition format
+const PROMETHEUS_CONTENT_TYPE string = "text/plain; version=0.0.4; charset=utf-8"
+
+// Prometheus metric families, one for each kind of column
+var metricFamilies = []struct {
+	name, help string
+}{
+	{`myq_rate`, `Rate of change per second of a status counter (RateCol, RateSumCol)`},
+	{`myq_diff`, `Change of a status counter since the previous sample (DiffCol)`},
+	{`myq_gauge`, `Current value of a status variable (GaugeCol, CurDiffCol)`},
+	{`myq_percent`, `Ratio of two status variables, as a percentage (PercentCol)`},
+	{`myq_value`, `Other numeric column values`},
+}
+
+// The metric family for a column
+func metricFamily(c Col) string {
+	switch c := c.(type) {
+	case ThresholdCol:
+		return metricFamily(c.ValueCol)
+	case RateCol, RateSumCol:
+		return `myq_rate`
+	case DiffCol:
+		return `myq_diff`
+	case GaugeCol, CurDiffCol:
+		return `myq_gauge`
+	case PercentCol:
+		return `myq_percent`
+	}
+	return `myq_value`
+}
+
+// Serves the numeric columns of all the given views as Prometheus metrics, calculated from the latest state
+type Exporter struct {
+	views map[string]View
+	mu    sync.Mutex
+	state *MyqState
+}
+
+func NewExporter(views map[string]View) *Exporter {
+	return &Exporter{views: views}
+}
+
+// Use this state for the next scrape
+func (e *Exporter) Update(state *MyqState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state = state
+}
+
+// Label values are quoted, so escape what would break out of the quotes
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Write the metrics for the latest state in the Prometheus text format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	state := e.state
+	e.mu.Unlock()
+
+	if state == nil {
+		http.Error(w, "No samples yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Views in a stable order
+	var names []string
+	for name := range e.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Collect the samples for each family, skipping anything that isn't a number
+	samples := map[string]*bytes.Buffer{}
+	for _, name := range names {
+		for _, cv := range viewValues(e.views[name], state) {
+			val, ok := cv.value.(float64)
+			if cv.time || !ok || !finite(val) {
+				continue
+			}
+
+			family := metricFamily(cv.col)
+			if samples[family] == nil {
+				samples[family] = new(bytes.Buffer)
+			}
+			fmt.Fprintf(samples[family], "%s{view=\"%s\",group=\"%s\",col=\"%s\"} %s\n", family,
+				labelEscaper.Replace(name), labelEscaper.Replace(cv.group), labelEscaper.Replace(cv.name),
+				strconv.FormatFloat(val, 'f', -1, 64))
+		}
+	}
+
+	w.Header().Set("Content-Type", PROMETHEUS_CONTENT_TYPE)
+	for _, family := range metricFamilies {
+		if buf, ok := samples[family.name]; ok {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", family.name, family.help, family.name)
+			buf.WriteTo(w)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package myqlib
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestExporter(t *testing.T) {
+	e := NewExporter(DefaultViews())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	// Nothing to serve before the first sample
+	if status, _ := scrape(t, server.URL); status != http.StatusServiceUnavailable {
+		t.Error("Expected 503 before any samples, got", status)
+	}
+
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state := range states {
+		e.Update(state)
+	}
+
+	status, body := scrape(t, server.URL+"/metrics")
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status", status, body)
+	}
+	for _, expected := range []string{
+		"# TYPE myq_rate gauge\n",
+		"myq_rate{view=\"cttf\",group=\"Connects\",col=\"cons\"} 7\n",
+		"myq_gauge{view=\"cttf\",group=\"Files\",col=\"open\"} 67\n",
+		"myq_gauge{view=\"innodb\",group=\"Buffer Pool\",col=\"data\"} 68411736064\n",
+		"myq_percent{view=\"innodb\",group=\"Buffer Pool\",col=\"dirt\"} 0.5422940211470105\n",
+		"myq_diff{view=\"throughput\",group=\"Throughput\",col=\"recv\"} ",
+		"myq_rate{view=\"coms\",group=\"\",col=\"sel\"} ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Error("Missing from metrics:", expected)
+		}
+	}
+	// Strings can't be metrics
+	if strings.Contains(body, `col="type"`) {
+		t.Error("Found a string column in the metrics")
+	}
+}
+
+func TestExporterFirstState(t *testing.T) {
+	e := NewExporter(DefaultViews())
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Update(<-states)
+
+	// The counters are totals since the server started, not rates yet
+	_, body := scrape(t, server.URL+"/metrics")
+	for _, family := range []string{"myq_rate{", "myq_diff{"} {
+		if strings.Contains(body, family) {
+			t.Error("Unexpected", family, "before there's a previous sample")
+		}
+	}
+	if !strings.Contains(body, "myq_gauge{view=\"cttf\",group=\"Files\",col=\"open\"} 67\n") {
+		t.Error("Missing gauges from the first sample")
+	}
+}
@@ -0,0 +1,309 @@
+package myqlib
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expression Columns calculate a number from an expression of status variables, like:
+//
+//	1 - rate(innodb_buffer_pool_reads)/rate(innodb_buffer_pool_read_requests)
+//
+// Names are the current value of a status variable, and the functions are:
+//
+//	rate(expr)  the rate of change per second of expr
+//	diff(expr)  the change in expr since the last sample
+//	cur(expr)   expr with current values (the default)
+//	var(name)   a server variable (the V_ keys from SHOW VARIABLES)
+//	sum(regex)  the sum of all the variables matching the regex
+type ExprCol struct {
+	DefaultCol
+	NumCol
+	expr string
+	root exprNode
+}
+
+func NewExprCol(name, help string, width int64, expr string, precision int64, units UnitsDef) (ExprCol, error) {
+	root, err := parseExpr(expr)
+	if err != nil {
+		return ExprCol{}, err
+	}
+	return ExprCol{DefaultCol{name, help, width}, NumCol{precision, units}, expr, root}, nil
+}
+
+func (c ExprCol) Value(state *MyqState) interface{} {
+	if val, err := c.root.eval(exprEnv{state, state.Cur}); err == nil && finite(val) {
+		return val
+	}
+	return nil
+}
+
+func (c ExprCol) Data(state *MyqState) chan string {
+	ch := make(chan string, 1)
+	defer close(ch)
+
+	if val, ok := c.Value(state).(float64); ok {
+		ch <- fit_string(collapse_number(val, c.Width(), c.precision, c.units), c.Width())
+	} else if _, err := c.root.eval(exprEnv{state, state.Cur}); err == errExprGap {
+		ch <- column_gap(c)
+	} else {
+		ch <- column_filler(c)
+	}
+	return ch
+}
+
+// Returned when an expression needs the previous sample but there's a gap, or it's the first sample
+var (
+	errExprGap   = errors.New("No previous sample")
+	errExprFirst = errors.New("No previous sample yet")
+)
+
+// What an expression is evaluated against
+type exprEnv struct {
+	state  *MyqState
+	sample MyqSample // where names are read from (state.Prev inside half of rate() and diff())
+}
+
+type exprNode interface {
+	eval(env exprEnv) (float64, error)
+}
+
+type exprNum float64
+
+func (n exprNum) eval(env exprEnv) (float64, error) { return float64(n), nil }
+
+// A status (or V_ variable) name
+type exprName string
+
+func (n exprName) eval(env exprEnv) (float64, error) {
+	return env.sample.getFloat(string(n))
+}
+
+type exprSum string // regex of the variables to sum
+
+func (s exprSum) eval(env exprEnv) (float64, error) {
+	return calculate_sum(env.sample, expand_variables([]string{string(s)}, env.state.Cur)), nil
+}
+
+type exprNeg struct {
+	arg exprNode
+}
+
+func (n exprNeg) eval(env exprEnv) (float64, error) {
+	val, err := n.arg.eval(env)
+	return -val, err
+}
+
+type exprBinary struct {
+	op          byte
+	left, right exprNode
+}
+
+func (b exprBinary) eval(env exprEnv) (float64, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	return left / right, nil // Inf or NaN on zero, the column will show filler
+}
+
+// rate(), diff() and cur()
+type exprFunc struct {
+	name string
+	arg  exprNode
+}
+
+func (f exprFunc) eval(env exprEnv) (float64, error) {
+	cur, err := f.arg.eval(exprEnv{env.state, env.state.Cur})
+	if err != nil || f.name == `cur` {
+		return cur, err
+	}
+
+	if env.state.Gap { // nothing to compare against
+		return 0, errExprGap
+	} else if env.state.Prev == nil {
+		return 0, errExprFirst
+	}
+	prev, _ := f.arg.eval(exprEnv{env.state, env.state.Prev}) // missing is 0, like other cols
+	if f.name == `diff` {
+		return calculate_diff(cur, prev), nil
+	}
+	return calculate_rate(cur, prev, env.state.SecondsDiff), nil
+}
+
+// Recursive descent parser for expressions
+type exprParser struct {
+	expr string
+	pos  int
+}
+
+func parseExpr(expr string) (exprNode, error) {
+	p := &exprParser{expr, 0}
+	node, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.expr) {
+		return nil, p.errorf("unexpected '%c'", p.expr[p.pos])
+	}
+	return node, nil
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("bad expression '%s' at %d: %s", p.expr, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// The next non-space character, or 0 at the end
+func (p *exprParser) peek() byte {
+	if p.skipSpace(); p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+// sum := product (('+' | '-') product)*
+func (p *exprParser) parseSum() (exprNode, error) {
+	node, err := p.parseProduct()
+	for err == nil && (p.peek() == '+' || p.peek() == '-') {
+		op := p.expr[p.pos]
+		p.pos++
+		var right exprNode
+		if right, err = p.parseProduct(); err == nil {
+			node = exprBinary{op, node, right}
+		}
+	}
+	return node, err
+}
+
+// product := unary (('*' | '/') unary)*
+func (p *exprParser) parseProduct() (exprNode, error) {
+	node, err := p.parseUnary()
+	for err == nil && (p.peek() == '*' || p.peek() == '/') {
+		op := p.expr[p.pos]
+		p.pos++
+		var right exprNode
+		if right, err = p.parseUnary(); err == nil {
+			node = exprBinary{op, node, right}
+		}
+	}
+	return node, err
+}
+
+// unary := '-' unary | primary
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		arg, err := p.parseUnary()
+		return exprNeg{arg}, err
+	}
+	return p.parsePrimary()
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// primary := number | name | function '(' ... ')' | '(' sum ')'
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, p.errorf("unexpected end")
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		p.pos++
+		return node, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.expr) && (p.expr[p.pos] == '.' || (p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9')) {
+			p.pos++
+		}
+		text := p.expr[start:p.pos]
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("bad number '%s'", text)
+		}
+		return exprNum(num), nil
+	case isNameChar(c):
+		start := p.pos
+		for p.pos < len(p.expr) && isNameChar(p.expr[p.pos]) {
+			p.pos++
+		}
+		name := strings.ToLower(p.expr[start:p.pos])
+		if p.peek() != '(' {
+			return exprName(name), nil
+		}
+		p.pos++
+		return p.parseCall(name)
+	}
+	return nil, p.errorf("unexpected '%c'", c)
+}
+
+// The arguments of a function, after the '('
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	switch name {
+	case `rate`, `diff`, `cur`:
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		p.pos++
+		return exprFunc{name, arg}, nil
+	case `var`, `sum`:
+		// Take everything up to the matching ')' as is, regexes can have parens too
+		start, depth := p.pos, 1
+		for ; p.pos < len(p.expr); p.pos++ {
+			if p.expr[p.pos] == '(' {
+				depth++
+			} else if p.expr[p.pos] == ')' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if depth != 0 {
+			return nil, p.errorf("missing ')'")
+		}
+		arg := strings.ToLower(strings.TrimSpace(p.expr[start:p.pos]))
+		p.pos++
+		if arg == "" {
+			return nil, p.errorf("%s() needs an argument", name)
+		}
+		if name == `var` {
+			return exprName(fmt.Sprint(VAR_PREFIX, arg)), nil
+		}
+		return exprSum(arg), nil
+	}
+	return nil, p.errorf("unknown function '%s'", name)
+}
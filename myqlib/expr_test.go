@@ -0,0 +1,97 @@
+package myqlib
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func testExprState() *MyqState {
+	return &MyqState{
+		Cur: MyqSample{`reads`: `30`, `read_requests`: `1000`, `bytes_sent`: `4096`, `questions`: `16`,
+			`com_insert`: `20`, `com_update`: `15`, `com_select`: `100`, `V_max_connections`: `151`},
+		Prev: MyqSample{`reads`: `10`, `read_requests`: `600`, `bytes_sent`: `1024`, `questions`: `8`,
+			`com_insert`: `10`, `com_update`: `5`, `com_select`: `50`},
+		SecondsDiff: 2,
+	}
+}
+
+func TestExprEval(t *testing.T) {
+	state := testExprState()
+	for expr, expected := range map[string]float64{
+		`1 - reads/read_requests`:                           0.97,
+		`bytes_sent/questions`:                              256,
+		`-reads + 2 * (3 + 1)`:                              -22,
+		`rate(reads)`:                                       10,
+		`diff(read_requests)`:                               400,
+		`1 - rate(reads)/rate(read_requests)`:               0.95,
+		`diff(bytes_sent)/diff(questions)`:                  384,
+		`cur(reads)`:                                        30,
+		`var(max_connections)`:                              151,
+		`sum(com_(insert|update))`:                          35,
+		`rate(sum(^com_))`:                                  35,
+		`diff(com_select) / 2 * 100 / var(MAX_connections)`: 25 * 100.0 / 151,
+		`.5 * 4`: 2,
+	} {
+		root, err := parseExpr(expr)
+		if err != nil {
+			t.Error(expr, err)
+			continue
+		}
+		val, err := root.eval(exprEnv{state, state.Cur})
+		if err != nil {
+			t.Error(expr, err)
+		} else if math.Abs(val-expected) > 0.000001 {
+			t.Error(expr, "expected", expected, "got", val)
+		}
+	}
+}
+
+func TestExprParseErrors(t *testing.T) {
+	for expr, expected := range map[string]string{
+		``:            `unexpected end`,
+		`reads +`:     `unexpected end`,
+		`(reads`:      `missing ')'`,
+		`rate(reads`:  `missing ')'`,
+		`sum(com_(x)`: `missing ')'`,
+		`reads reads`: `unexpected 'r'`,
+		`1.2.3`:       `bad number '1.2.3'`,
+		`max(reads)`:  `unknown function 'max'`,
+		`var()`:       `var() needs an argument`,
+		`reads % 2`:   `unexpected '%'`,
+	} {
+		if _, err := parseExpr(expr); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected '%s' for '%s', got %v", expected, expr, err)
+		}
+	}
+}
+
+func TestExprCol(t *testing.T) {
+	col, err := NewExprCol(`hit`, `Buffer pool hit ratio`, 5, `100 * (1 - rate(reads)/rate(read_requests))`, 1, PercentUnits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := testExprState()
+	if data := <-col.Data(state); data != `95.0%` {
+		t.Errorf("Unexpected data: '%s'", data)
+	}
+
+	// Missing variables and dividing by zero are filler
+	missing, _ := NewExprCol(`x`, `Missing`, 4, `nothere / 2`, 0, NumberUnits)
+	zero, _ := NewExprCol(`x`, `Zero`, 4, `reads / (questions - 16)`, 0, NumberUnits)
+	for _, c := range []ExprCol{missing, zero} {
+		if data := <-c.Data(state); data != `   -` {
+			t.Errorf("Unexpected data for %s: '%s'", c.expr, data)
+		}
+	}
+
+	// Only expressions that need the previous sample have a gap
+	state.Gap = true
+	if data := <-col.Data(state); data != `  gap` {
+		t.Errorf("Unexpected gap data: '%s'", data)
+	}
+	cur, _ := NewExprCol(`x`, `Current`, 4, `reads`, 0, NumberUnits)
+	if data := <-cur.Data(state); data != `  30` {
+		t.Errorf("Unexpected gap data: '%s'", data)
+	}
+}
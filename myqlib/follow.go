@@ -0,0 +1,86 @@
+package myqlib
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// How often to check a followed file for more data
+const FOLLOW_POLL time.Duration = 250 * time.Millisecond
+
+// Reads a file like 'tail -F': it waits for more data at EOF (so it never returns io.EOF),
+// starts over if the file is truncated and switches to the new file if it is rotated.
+type followReader struct {
+	filename string
+	file     *os.File
+	offset   int64
+	poll     time.Duration
+}
+
+func openFollow(filename string) (*followReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &followReader{filename, file, 0, FOLLOW_POLL}, nil
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		// At the end of the file, see if it moved on without us
+		if reopened, err := r.checkFile(); err != nil {
+			return 0, err
+		} else if !reopened {
+			time.Sleep(r.poll)
+		}
+	}
+}
+
+// Reopen or rewind the file if it was rotated or truncated, returns true if it did either
+func (r *followReader) checkFile() (bool, error) {
+	named, err := os.Stat(r.filename)
+	if os.IsNotExist(err) {
+		return false, nil // rotated away, wait for the new one to show up
+	} else if err != nil {
+		return false, err
+	}
+	current, err := r.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(named, current) {
+		// Rotated, and we've already read everything from the old one
+		file, err := os.Open(r.filename)
+		if err != nil {
+			return false, err
+		}
+		r.file.Close()
+		r.file, r.offset = file, 0
+		return true, nil
+	}
+
+	if current.Size() < r.offset {
+		// Truncated, start over from the beginning
+		if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		r.offset = 0
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *followReader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,286 @@
+package myqlib
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	INNODB_STATUS_COMMAND string = "SHOW ENGINE INNODB STATUS"
+
+	// prefix of the keys parsed out of SHOW ENGINE INNODB STATUS
+	INNODB_STATUS_PREFIX = "innodb_status_"
+
+	// Layout of the time at the top of the output (and of the latest deadlock)
+	INNODB_STATUS_TIME_LAYOUT string = "2006-01-02 15:04:05"
+)
+
+// SHOW ENGINE INNODB STATUS (which needs the PROCESS privilege) for live loaders to run too
+var InnodbStatusQuery = ExtraQuery{query: INNODB_STATUS_COMMAND, add: func(sample MyqSample, row map[string]string) {
+	addInnodbStatus(sample, row[`Status`])
+}}
+
+// Lines we take numbers from, the keys are named by the regexp's subexpressions
+var innodbStatusLines = []*regexp.Regexp{
+	regexp.MustCompile(`reservation count (?P<os_wait_reservation_count>\d+)`),
+	regexp.MustCompile(`signal count (?P<os_wait_signal_count>\d+)`),
+	regexp.MustCompile(`^RW-shared spins (?P<rw_shared_spins>\d+), rounds (?P<rw_shared_rounds>\d+), OS waits (?P<rw_shared_os_waits>\d+)`),
+	regexp.MustCompile(`^RW-excl spins (?P<rw_excl_spins>\d+), rounds (?P<rw_excl_rounds>\d+), OS waits (?P<rw_excl_os_waits>\d+)`),
+	regexp.MustCompile(`^RW-sx spins (?P<rw_sx_spins>\d+), rounds (?P<rw_sx_rounds>\d+), OS waits (?P<rw_sx_os_waits>\d+)`),
+	regexp.MustCompile(`^Mutex spin waits (?P<mutex_spin_waits>\d+), rounds (?P<mutex_rounds>\d+), OS waits (?P<mutex_os_waits>\d+)`),
+	regexp.MustCompile(`^Trx id counter (?P<trx_id_counter>\d+)`),
+	regexp.MustCompile(`^History list length (?P<history_list_length>\d+)`),
+	regexp.MustCompile(`^Pending flushes \(fsync\) log: (?P<pending_log_flushes>\d+); buffer pool: (?P<pending_buffer_pool_flushes>\d+)`),
+	regexp.MustCompile(`^Pending reads\s+(?P<pending_reads>\d+)`),
+	regexp.MustCompile(`^Pending writes: LRU (?P<pending_writes_lru>\d+), flush list (?P<pending_writes_flush_list>\d+), single page (?P<pending_writes_single_page>\d+)`),
+	regexp.MustCompile(`^(?P<queries_inside>\d+) queries inside InnoDB, (?P<queries_queued>\d+) queries in queue`),
+	regexp.MustCompile(`^(?P<read_views>\d+) read views open inside InnoDB`),
+}
+
+// Log positions are one number, or a high and low 32 bit pair in old versions
+var innodbStatusLog = map[string]string{
+	`Log sequence number`: `log_sequence_number`,
+	`Log flushed up to`:   `log_flushed_up_to`,
+	`Pages flushed up to`: `pages_flushed_up_to`,
+	`Last checkpoint at`:  `last_checkpoint_at`,
+}
+
+var (
+	innodbStatusSemaphore = regexp.MustCompile(`^--Thread \d+ has waited at .* for ([\d.]+) seconds the semaphore`)
+	innodbStatusActive    = regexp.MustCompile(`^---TRANSACTION .*ACTIVE (?:\(PREPARED\) )?(\d+) sec`)
+	innodbStatusAio       = regexp.MustCompile(`^Pending normal aio reads:(.*), aio writes:(.*)`)
+	innodbStatusNumber    = regexp.MustCompile(`\d+`)
+)
+
+// The key of a SHOW ENGINE INNODB STATUS value in a sample
+func innodbStatusKey(name string) string {
+	return fmt.Sprint(INNODB_STATUS_PREFIX, name)
+}
+
+// Parse the free-form output of SHOW ENGINE INNODB STATUS into sample keys
+func addInnodbStatus(sample MyqSample, text string) {
+	// The mysql cli escapes newlines in BATCH output that isn't vertical
+	if !strings.Contains(text, "\n") && strings.Contains(text, `\n`) {
+		text = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\\`, `\`).Replace(text)
+	}
+
+	set := func(name string, val float64) {
+		sample[innodbStatusKey(name)] = strconv.FormatFloat(val, 'f', -1, 64)
+	}
+
+	// These are counted, so they're 0 if we don't see any
+	counts := map[string]float64{
+		`semaphore_waits`: 0, `longest_semaphore_wait`: 0,
+		`transactions`: 0, `active_transactions`: 0, `longest_transaction`: 0, `lock_waits`: 0,
+	}
+
+	var section, before, prev string
+	var started bool // prev closed a section header
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // queries in the transaction list can be long
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+
+		// The first line of the deadlock section is when it happened
+		if started && section == `LATEST DETECTED DEADLOCK` {
+			if fields := strings.Fields(line); len(fields) >= 2 && strings.Contains(fields[1], `:`) {
+				sample[innodbStatusKey(`latest_deadlock`)] = fmt.Sprint(fields[0], ` `, fields[1])
+			}
+		}
+
+		// Section headers are a title between two lines of dashes
+		started = isDashes(line) && isDashes(before) && prev != "" && !isDashes(prev)
+		if started {
+			section = prev
+		}
+		before, prev = prev, line
+
+		for _, re := range innodbStatusLines {
+			if match := re.FindStringSubmatch(line); match != nil {
+				for i, name := range re.SubexpNames() {
+					if name != "" {
+						sample[innodbStatusKey(name)] = match[i]
+					}
+				}
+			}
+		}
+		for prefix, name := range innodbStatusLog {
+			if strings.HasPrefix(line, prefix) {
+				if nums := innodbStatusNumber.FindAllString(line[len(prefix):], 2); len(nums) == 2 {
+					high, _ := strconv.ParseFloat(nums[0], 64)
+					low, _ := strconv.ParseFloat(nums[1], 64)
+					set(name, high*(1<<32)+low)
+				} else if len(nums) == 1 {
+					sample[innodbStatusKey(name)] = nums[0]
+				}
+			}
+		}
+
+		if match := innodbStatusAio.FindStringSubmatch(line); match != nil {
+			set(`pending_aio_reads`, sumPending(match[1]))
+			set(`pending_aio_writes`, sumPending(match[2]))
+		}
+
+		if match := innodbStatusSemaphore.FindStringSubmatch(line); match != nil {
+			counts[`semaphore_waits`]++
+			if secs, _ := strconv.ParseFloat(match[1], 64); secs > counts[`longest_semaphore_wait`] {
+				counts[`longest_semaphore_wait`] = secs
+			}
+		}
+
+		if section == `TRANSACTIONS` {
+			if strings.HasPrefix(line, `---TRANSACTION `) {
+				counts[`transactions`]++
+			}
+			if match := innodbStatusActive.FindStringSubmatch(line); match != nil {
+				counts[`active_transactions`]++
+				if secs, _ := strconv.ParseFloat(match[1], 64); secs > counts[`longest_transaction`] {
+					counts[`longest_transaction`] = secs
+				}
+			}
+			if strings.HasPrefix(line, `LOCK WAIT `) {
+				counts[`lock_waits`]++
+			}
+		}
+	}
+
+	for name, val := range counts {
+		set(name, val)
+	}
+}
+
+// Section headers are underlined (and overlined) with dashes
+func isDashes(line string) bool {
+	return len(line) >= 3 && strings.Trim(line, `-`) == ""
+}
+
+// Pending aio is a total, a total and per thread counts in brackets, or just the brackets.  Use the brackets if there are any.
+func sumPending(s string) (sum float64) {
+	if open := strings.Index(s, `[`); open >= 0 {
+		s = s[open:]
+		if end := strings.Index(s, `]`); end >= 0 {
+			s = s[:end]
+		}
+	} else if nums := innodbStatusNumber.FindAllString(s, 1); len(nums) == 1 {
+		s = nums[0]
+	}
+	for _, num := range innodbStatusNumber.FindAllString(s, -1) {
+		val, _ := strconv.ParseFloat(num, 64)
+		sum += val
+	}
+	return
+}
+
+// When SHOW ENGINE INNODB STATUS was run, from its first line (in the server's time zone)
+func innodbStatusTime(text string, loc *time.Location) (time.Time, bool) {
+	for _, line := range strings.SplitN(text, "\n", 4) {
+		if strings.Contains(line, `INNODB MONITOR OUTPUT`) && len(line) >= len(INNODB_STATUS_TIME_LAYOUT) {
+			t, err := time.ParseInLocation(INNODB_STATUS_TIME_LAYOUT, line[:len(INNODB_STATUS_TIME_LAYOUT)], loc)
+			return t, err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
+// Load a file of SHOW ENGINE INNODB STATUS\G output (like pt-stalk's innodbstatus files) from a server in loc
+func loadInnodbStatus(filename string, loc *time.Location) (sample MyqSample, taken time.Time, err error) {
+	file, err := openCapture(filename)
+	if err != nil {
+		return nil, taken, err
+	}
+	defer file.Close()
+
+	var vertical verticalRows
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		vertical.parseLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, taken, err
+	}
+	if len(vertical.monitor) == 0 {
+		return nil, taken, fmt.Errorf("%s: no INNODB MONITOR OUTPUT", filename)
+	}
+
+	text := strings.Join(vertical.monitor, "\n")
+	sample = MyqSample{}
+	addInnodbStatus(sample, text)
+	taken, _ = innodbStatusTime(text, loc)
+	return sample, taken, nil
+}
+
+// Copy the SHOW ENGINE INNODB STATUS keys of one sample to another
+func (s MyqSample) copyInnodbStatus(from MyqSample) {
+	for key, value := range from {
+		if strings.HasPrefix(key, INNODB_STATUS_PREFIX) {
+			s[key] = value
+		}
+	}
+}
+
+// 1 if a deadlock happened since the previous sample
+func new_deadlock(state *MyqState) interface{} {
+	key := innodbStatusKey(`latest_deadlock`)
+	cur, cerr := state.Cur.getString(key)
+	if state.Prev == nil || cerr != nil {
+		return 0.0
+	}
+	if state.Prev.getStr(key) != cur {
+		return 1.0
+	}
+	return 0.0
+}
+
+// The innodb_status view's columns
+func innodb_status_cols() []Col {
+	key := innodbStatusKey
+	return []Col{
+		NewGroupCol(`Pending`, `Pending IO`,
+			NewGaugeCol(`aior`, `Pending normal aio reads`, 4, key(`pending_aio_reads`), 0, NumberUnits),
+			NewGaugeCol(`aiow`, `Pending normal aio writes`, 4, key(`pending_aio_writes`), 0, NumberUnits),
+			NewGaugeCol(`lfsh`, `Pending log fsyncs`, 4, key(`pending_log_flushes`), 0, NumberUnits),
+			NewGaugeCol(`bfsh`, `Pending buffer pool fsyncs`, 4, key(`pending_buffer_pool_flushes`), 0, NumberUnits),
+			NewGaugeCol(`rd`, `Pending buffer pool reads`, 4, key(`pending_reads`), 0, NumberUnits),
+			NewGaugeCol(`flst`, `Pending flush list writes`, 4, key(`pending_writes_flush_list`), 0, NumberUnits),
+		),
+		NewGroupCol(`Semaphores`, `Semaphore waits`,
+			NewGaugeCol(`wait`, `Threads waiting for a semaphore`, 4, key(`semaphore_waits`), 0, NumberUnits),
+			with_thresholds(NewGaugeCol(`long`, `Longest semaphore wait`, 4, key(`longest_semaphore_wait`), 0, SecondUnits), `>60`, `>240`),
+			NewRateCol(`oswt`, `OS waits (reservations) per second`, 4, key(`os_wait_reservation_count`), 0, NumberUnits),
+			NewRateSumCol(`spin`, `Spin rounds per second`, 4, 0, NumberUnits, key(`mutex_rounds`), key(`rw_shared_rounds`), key(`rw_excl_rounds`), key(`rw_sx_rounds`)),
+		),
+		NewGroupCol(`Transactions`, `Transactions and locking`,
+			NewGaugeCol(`trxs`, `Transactions`, 4, key(`transactions`), 0, NumberUnits),
+			NewGaugeCol(`actv`, `Active transactions`, 4, key(`active_transactions`), 0, NumberUnits),
+			NewGaugeCol(`lckw`, `Transactions in LOCK WAIT`, 4, key(`lock_waits`), 0, NumberUnits),
+			with_thresholds(NewGaugeCol(`old`, `Longest active transaction`, 4, key(`longest_transaction`), 0, SecondUnits), `>60`, `>600`),
+			with_thresholds(NewGaugeCol(`Hist`, `History List Length`, 5, key(`history_list_length`), 0, NumberUnits), `>100000`, `>500000`),
+		),
+		NewGroupCol(`Queries`, `Queries inside InnoDB`,
+			NewGaugeCol(`in`, `Queries inside InnoDB`, 4, key(`queries_inside`), 0, NumberUnits),
+			NewGaugeCol(`que`, `Queries in the queue`, 4, key(`queries_queued`), 0, NumberUnits),
+		),
+		NewGroupCol(`Log`, `Redo log`,
+			NewCurDiffCol(`unfl`, `Log written but not flushed`, 5, key(`log_sequence_number`), key(`log_flushed_up_to`), 0, MemoryUnits),
+			NewCurDiffCol(`ckpt`, `Checkpoint age`, 5, key(`log_sequence_number`), key(`last_checkpoint_at`), 0, MemoryUnits),
+		),
+		NewGroupCol(`Deadlock`, `Latest detected deadlock`,
+			with_thresholds(NewFuncValueCol(`new`, `A deadlock since the last sample`, 3, func(state *MyqState, c Col) chan string {
+				ch := make(chan string, 1)
+				defer close(ch)
+				if new_deadlock(state) == 1.0 {
+					ch <- fit_string(`NEW`, c.Width())
+				} else {
+					ch <- column_filler(c)
+				}
+				return ch
+			}, new_deadlock), ``, `>0`),
+			NewRightmostCol(`last`, `Time of the latest deadlock`, 8, key(`latest_deadlock`)),
+		),
+	}
+}
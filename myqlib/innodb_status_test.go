@@ -0,0 +1,140 @@
+package myqlib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+const innodbStatusFile = "../testdata/pt-stalk/2014_10_24_13_34_40-innodbstatus1"
+
+func TestInnodbStatus(t *testing.T) {
+	sample, taken, err := loadInnodbStatus(innodbStatusFile, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := time.ParseInLocation(INNODB_STATUS_TIME_LAYOUT, "2014-10-24 13:34:40", time.Local); !taken.Equal(expected) {
+		t.Error("Unexpected time:", taken)
+	}
+
+	expected := map[string]string{
+		`os_wait_reservation_count`: `107342`,
+		`mutex_rounds`:              `1537893`,
+		`rw_excl_os_waits`:          `29874`,
+		`semaphore_waits`:           `2`,
+		`longest_semaphore_wait`:    `12`,
+		`latest_deadlock`:           `2014-10-24 13:20:07`,
+		`history_list_length`:       `1803`,
+		`transactions`:              `3`,
+		`active_transactions`:       `2`,
+		`longest_transaction`:       `305`,
+		`lock_waits`:                `1`, // not the one in the deadlock
+		`pending_aio_reads`:         `0`,
+		`pending_log_flushes`:       `0`,
+		`pending_writes_flush_list`: `0`,
+		`log_sequence_number`:       `6871376308`, // 1<<32 + 2576409012
+		`last_checkpoint_at`:        `6843703596`,
+		`queries_inside`:            `4`,
+		`queries_queued`:            `1`,
+		`read_views`:                `2`,
+	}
+	for name, value := range expected {
+		if got := sample.getStr(innodbStatusKey(name)); got != value {
+			t.Error(name, "expected", value, "got", got)
+		}
+	}
+	if sample.getStr(`type`) != "" || sample.getStr(`repl_type`) != "" {
+		t.Error("The monitor's own row leaked into the sample:", sample)
+	}
+}
+
+func TestSumPending(t *testing.T) {
+	tests := map[string]float64{
+		` 0 [0, 0, 0, 0] `: 0,
+		` 3 [1, 0, 2, 0] `: 3,
+		` [0, 1] [2, 0] `:  1, // just the first set of brackets
+		` 7`:               7,
+		``:                 0,
+	}
+	for s, expected := range tests {
+		if got := sumPending(s); got != expected {
+			t.Errorf("%q expected %v got %v", s, expected, got)
+		}
+	}
+}
+
+// The mysql cli's batch output of SHOW ENGINE INNODB STATUS\G SHOW GLOBAL STATUS
+func TestInnodbStatusBatch(t *testing.T) {
+	monitor, err := ioutil.ReadFile(innodbStatusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := bytes.NewBuffer(monitor)
+	buffer.WriteString("Variable_name\tValue\nUptime\t100\nThreads_running\t3\n")
+
+	ch := make(chan MyqSample, 1)
+	parseBatch(ch, buffer, BATCH, "")
+	sample := <-ch
+	if sample.getStr(`uptime`) != `100` || sample.getStr(`threads_running`) != `3` {
+		t.Error("Missing status:", sample)
+	}
+	if sample.getStr(innodbStatusKey(`history_list_length`)) != `1803` {
+		t.Error("Missing InnoDB status:", sample)
+	}
+	for key := range sample {
+		if strings.HasPrefix(key, REPL_PREFIX) {
+			t.Error("Unexpected replication key:", key)
+		}
+	}
+
+	// Escaped, like it is without \G
+	escaped := MyqSample{}
+	addInnodbStatus(escaped, strings.Replace(string(monitor), "\n", `\n`, -1))
+	if escaped.getStr(innodbStatusKey(`lock_waits`)) != `1` {
+		t.Error("Unexpected escaped lock waits:", escaped.getStr(innodbStatusKey(`lock_waits`)))
+	}
+}
+
+func TestInnodbStatusView(t *testing.T) {
+	l, err := NewStalkLoader(1*time.Second, "../testdata/pt-stalk", "2014_10_24_13_34_40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := <-states, <-states
+
+	// Each sample gets the status collected by its time
+	if first.Cur.getStr(innodbStatusKey(`history_list_length`)) != `1803` || second.Cur.getStr(innodbStatusKey(`history_list_length`)) != `1811` {
+		t.Error("Unexpected history list lengths:", first.Cur.getStr(innodbStatusKey(`history_list_length`)), second.Cur.getStr(innodbStatusKey(`history_list_length`)))
+	}
+
+	v := DefaultViews()[`innodb_status`]
+	v.SetTimeCol(&Runtime_col)
+	var lines []string
+	for _, state := range []*MyqState{first, second} {
+		for line := range v.Data(state) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 2 {
+		t.Fatal("Expected 2 lines, got", lines)
+	}
+	if strings.Contains(lines[0], `NEW`) || !strings.HasSuffix(lines[1], `NEW 13:34:41`) {
+		t.Errorf("Expected a new deadlock in the second sample only: %q %q", lines[0], lines[1])
+	}
+	if !strings.Contains(lines[1], `   2    3    1    0`) {
+		t.Errorf("Unexpected pending IO: %q", lines[1])
+	}
+
+	if val := new_deadlock(second); val != 1.0 {
+		t.Error("Expected a new deadlock, got", val)
+	}
+	if val := new_deadlock(&MyqState{Cur: second.Cur, Prev: second.Cur}); val != 0.0 {
+		t.Error("Expected no new deadlock, got", val)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
+	"io"
+	"math"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -26,6 +27,9 @@ const (
 
 	// prefix of SHOW VARIABLES keys, they are stored (if available) in the same map as the status variables
 	VAR_PREFIX = "V_"
+
+	// key of the time the sample was taken (unix epoch, possibly with a fraction), if known
+	TIMESTAMP_KEY = "TS"
 )
 
 // Build the argument list
@@ -35,12 +39,31 @@ var MYSQLCLIARGS []string = []string{
 	"-N", // Skip column names
 }
 
+// Loaders send any errors they hit once collection has started to the errs channel
 type Loader interface {
-	getStatus() (chan MyqSample, error)
-	getVars() (chan MyqSample, error)
+	getStatus(errs chan error) (chan MyqSample, error)
+	getVars(errs chan error) (chan MyqSample, error)
 	getInterval() time.Duration
 }
 
+// Errors from a Loader after collection has started
+type LoaderError struct {
+	Source string // the command or file being harvested
+	Err    error
+}
+
+func (e *LoaderError) Error() string {
+	return fmt.Sprint(e.Source, ": ", e.Err)
+}
+
+// Send an error from the given source to errs, without blocking (dropped if errs is full)
+func sendError(errs chan error, source string, err error) {
+	select {
+	case errs <- &LoaderError{source, err}:
+	default: // nobody is listening, or they have an error to exit on already
+	}
+}
+
 // MyqSamples are K->V maps
 type MyqSample map[string]string
 
@@ -98,6 +121,23 @@ func (s MyqSample) getStr(key string) string {
 	return str
 }
 
+// When the sample was taken, or an error if we don't know
+func (s MyqSample) getTimestamp() (time.Time, error) {
+	epoch, err := s.getFloat(TIMESTAMP_KEY)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Round to microseconds, floats can't hold nanoseconds for current epochs
+	sec := math.Floor(epoch)
+	usec := math.Floor((epoch-sec)*1e6 + 0.5)
+	return time.Unix(int64(sec), int64(usec)*int64(time.Microsecond)), nil
+}
+
+// Set the time the sample was taken
+func (s MyqSample) setTimestamp(t time.Time) {
+	s[TIMESTAMP_KEY] = fmt.Sprintf("%d.%03d", t.Unix(), t.Nanosecond()/int(time.Millisecond))
+}
+
 // Gets either a float or an int (check type of result), or an error
 func (s MyqSample) getNumeric(key string) (interface{}, error) {
 	if val, err := s.getInt(key); err != nil {
@@ -115,24 +155,51 @@ type MyqState struct {
 	Cur, Prev   MyqSample
 	SecondsDiff float64 // Difference between Cur and Prev
 	FirstUptime int64   // Uptime of our first sample this run
+	Gap         bool    // Collection was interrupted between Prev and Cur, so their differences are meaningless
+	Annotation  string  // Why there was a gap, if we know (RESTART or FLUSH)
+}
+
+// MyqState Annotations
+const (
+	RESTART string = "restart" // uptime went backwards
+	FLUSH   string = "flush"   // uptime_since_flush_status went backwards (FLUSH STATUS)
+)
+
+// Check if the server restarted or had its status flushed between the two samples, returns the Annotation or ""
+func detectReset(cur, prev MyqSample) string {
+	if curup, err := cur.getFloat(`uptime`); err == nil {
+		if preup, err := prev.getFloat(`uptime`); err == nil && curup < preup {
+			return RESTART
+		}
+	}
+	if curflush, err := cur.getFloat(`uptime_since_flush_status`); err == nil {
+		if preflush, err := prev.getFloat(`uptime_since_flush_status`); err == nil && curflush < preflush {
+			return FLUSH
+		}
+	}
+	return ""
 }
 
-// Given a loader, get a channel of myqstates being returned
-func GetState(l Loader) (chan *MyqState, error) {
+// Given a loader, get a channel of myqstates being returned.  Errors the loader hits after this
+// returns come back on the error channel; the state channel is closed if collection can't continue.
+func GetState(l Loader) (chan *MyqState, chan error, error) {
+	// Buffered so a failing loader doesn't block if the caller is busy with a state
+	var errs = make(chan error, 2)
+
 	// First getVars, if possible
 	var latestvars MyqSample // whatever the last vars sample is will be here (may be empty)
-	varsch, varserr := l.getVars()
+	varsch, varserr := l.getVars(errs)
 	// return the error if getVars fails, but not if it's just due to a missing file
 	if varserr != nil && varserr.Error() != "No file given" {
 		// Serious error
-		return nil, varserr
+		return nil, nil, varserr
 	}
 
 	// Now getStatus
 	var ch = make(chan *MyqState)
-	statusch, statuserr := l.getStatus()
+	statusch, statuserr := l.getStatus(errs)
 	if statuserr != nil {
-		return nil, statuserr
+		return nil, nil, statuserr
 	}
 
 	// Main status loop
@@ -141,10 +208,18 @@ func GetState(l Loader) (chan *MyqState, error) {
 
 		var prev MyqSample
 		var firstUptime int64
+		var gap bool
 		for status := range statusch {
+			// A nil sample means the loader lost its connection, flag the next state
+			if status == nil {
+				gap = true
+				continue
+			}
+
 			// Init new state
 			state := new(MyqState)
 			state.Cur = status
+			state.Gap = gap
 
 			// Only needed for File loaders really
 			if firstUptime == 0 {
@@ -156,13 +231,21 @@ func GetState(l Loader) (chan *MyqState, error) {
 			if prev != nil {
 				state.Prev = prev
 
+				// Counters start over after a restart or FLUSH STATUS, so this sample is a new baseline
+				if annotation := detectReset(status, prev); annotation != "" {
+					state.Gap = true
+					state.Annotation = annotation
+					if annotation == RESTART {
+						firstUptime, _ = status.getInt(`uptime`)
+						state.FirstUptime = firstUptime
+					}
+				}
+
 				// Calcuate timediff if there is a prev.  Only file loader?
-				curup, _ := status.getFloat(`uptime`)
-				preup, _ := prev.getFloat(`uptime`)
-				state.SecondsDiff = curup - preup
+				state.SecondsDiff = secondsDiff(status, prev, l.getInterval())
 
-				// Skip to the next sample if SecondsDiff is < the interval
-				if state.SecondsDiff < l.getInterval().Seconds() {
+				// Skip to the next sample if SecondsDiff is < the interval (unless we have to report a gap)
+				if !state.Gap && state.SecondsDiff < l.getInterval().Seconds() {
 					continue
 				}
 			}
@@ -171,7 +254,7 @@ func GetState(l Loader) (chan *MyqState, error) {
 			if varserr == nil {
 				// get some new vars, or skip if the varsch is closed
 				newvars, ok := <-varsch
-				if ok {
+				if ok && newvars != nil {
 					latestvars = newvars
 				}
 			}
@@ -187,10 +270,27 @@ func GetState(l Loader) (chan *MyqState, error) {
 
 			// Set the state for the next round
 			prev = status
+			gap = false
 		}
 	}()
 
-	return ch, nil
+	return ch, errs, nil
+}
+
+// Seconds between two samples by their uptime, or by their timestamps (to the second, like uptime)
+// for samples without it, like processlist captures.  The interval if we have neither.
+func secondsDiff(cur, prev MyqSample, interval time.Duration) float64 {
+	curup, cerr := cur.getFloat(`uptime`)
+	preup, perr := prev.getFloat(`uptime`)
+	if cerr == nil && perr == nil {
+		return curup - preup
+	}
+	curts, cerr := cur.getFloat(TIMESTAMP_KEY)
+	prets, perr := prev.getFloat(TIMESTAMP_KEY)
+	if cerr == nil && perr == nil {
+		return math.Floor(curts - prets + 0.5)
+	}
+	return interval.Seconds()
 }
 
 type loaderInterval time.Duration
@@ -199,18 +299,111 @@ func (l loaderInterval) getInterval() time.Duration {
 	return time.Duration(l)
 }
 
+// Longest we wait between reconnect attempts
+const MAX_BACKOFF time.Duration = time.Minute
+
+// Reconnect settings for live loaders
+type loaderReconnect struct {
+	retries int           // reconnect attempts in a row before giving up (0 never reconnects)
+	backoff time.Duration // wait before the first attempt, doubled for each one after
+}
+
+// Try to reconnect up to retries times in a row if the connection is lost
+func (r *loaderReconnect) SetReconnect(retries int, backoff time.Duration) {
+	r.retries, r.backoff = retries, backoff
+}
+
+// A query live loaders run along with SHOW GLOBAL STATUS, with its rows added to the same sample
+// (like ReplicationQuery or DigestsQuery).  The mysql cli's vertical output of it is picked out by parseBatch.
+type ExtraQuery struct {
+	query    string
+	fallback string                                        // run instead if query fails, like on older servers ("" for none)
+	add      func(sample MyqSample, row map[string]string) // add one row to the status sample
+}
+
+// The extra queries live loaders run (see SetExtraQueries)
+type loaderExtraQueries []ExtraQuery
+
+// Also run these queries with every status sample
+func (q *loaderExtraQueries) SetExtraQueries(queries ...ExtraQuery) {
+	*q = queries
+}
+
+// Run session (which connects and sends samples until the connection fails) again every time it
+// fails until we run out of retries.  A nil sample is sent to ch whenever a session is lost.
+func (r loaderReconnect) keepAlive(ch chan MyqSample, errs chan error, source string, session func(chan MyqSample) error) {
+	attempt := 0
+	for {
+		// Relay the samples so we know if this session got anywhere
+		sessionch := make(chan MyqSample)
+		done := make(chan error, 1)
+		go func() {
+			defer close(sessionch)
+			done <- session(sessionch)
+		}()
+
+		for sample := range sessionch {
+			if _, ok := sample[TIMESTAMP_KEY]; !ok {
+				sample.setTimestamp(time.Now())
+			}
+			ch <- sample
+			attempt = 0
+		}
+
+		err := <-done
+		if err == nil {
+			return // session ended normally
+		}
+		if attempt >= r.retries {
+			sendError(errs, source, err)
+			return
+		}
+
+		// Wait a bit longer after every failed attempt
+		if attempt == 0 {
+			ch <- nil
+		}
+		wait := r.backoff << uint(attempt)
+		if wait > MAX_BACKOFF || wait <= 0 {
+			wait = MAX_BACKOFF
+		}
+		time.Sleep(wait)
+		attempt++
+	}
+}
+
 // Load mysql status output from a mysqladmin output file
 type FileLoader struct {
 	loaderInterval
 	statusFile    string
 	variablesFile string
+	window        SampleWindow // which samples from statusFile to use
+	follow        bool         // keep reading statusFile as it grows
 }
 
 func NewFileLoader(i time.Duration, statusFile, varFile string) *FileLoader {
-	return &FileLoader{loaderInterval(i), statusFile, varFile}
+	return &FileLoader{loaderInterval(i), statusFile, varFile, SampleWindow{}, false}
+}
+
+// Only load the status samples in the given window.  Variables have no uptime, so only the time
+// range applies to them, and only if their file has timestamps too.
+func (l *FileLoader) SetWindow(w SampleWindow) {
+	l.window = w
+}
+
+// Keep waiting for more samples at the end of the status file, like 'tail -F'
+func (l *FileLoader) SetFollow(follow bool) {
+	l.follow = follow
 }
-func (l FileLoader) harvestFile(filename string) (chan MyqSample, error) {
-	file, err := os.OpenFile(filename, os.O_RDONLY, 0)
+
+func (l FileLoader) harvestFile(filename string, window SampleWindow, follow bool, errs chan error) (chan MyqSample, error) {
+	var file io.ReadCloser
+	var err error
+	if follow && filename != STDIN_FILE {
+		file, err = openFollow(filename)
+	} else {
+		file, err = openCapture(filename)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -221,19 +414,21 @@ func (l FileLoader) harvestFile(filename string) (chan MyqSample, error) {
 	go func() {
 		defer file.Close()
 		defer close(ch)
-		parseSamples(file, ch, l.loaderInterval.getInterval())
+		if err := parseSamples(file, ch, l.loaderInterval.getInterval(), window); err != nil {
+			sendError(errs, filename, err)
+		}
 	}()
 
 	return ch, nil
 }
 
-func (l FileLoader) getStatus() (chan MyqSample, error) {
-	return l.harvestFile(l.statusFile)
+func (l FileLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	return l.harvestFile(l.statusFile, l.window, l.follow, errs)
 }
 
-func (l FileLoader) getVars() (chan MyqSample, error) {
+func (l FileLoader) getVars(errs chan error) (chan MyqSample, error) {
 	if l.variablesFile != "" {
-		return l.harvestFile(l.variablesFile)
+		return l.harvestFile(l.variablesFile, SampleWindow{Start: l.window.Start, End: l.window.End}, false, errs)
 	} else {
 		return nil, errors.New("No file given")
 	}
@@ -242,15 +437,17 @@ func (l FileLoader) getVars() (chan MyqSample, error) {
 // SHOW output via mysqladmin on a live server
 type LiveLoader struct {
 	loaderInterval
+	loaderReconnect
+	loaderExtraQueries
 	args string // other args for mysqladmin (like -u, -p, -h, etc.)
 }
 
 func NewLiveLoader(i time.Duration, args string) *LiveLoader {
-	return &LiveLoader{loaderInterval(i), args}
+	return &LiveLoader{loaderInterval: loaderInterval(i), args: args}
 }
 
 // Collect output from MYSQLCLI and send it back in a sample
-func (l LiveLoader) harvestMySQL(command string) (chan MyqSample, error) {
+func (l LiveLoader) harvestMySQL(command string, errs chan error) (chan MyqSample, error) {
 	// Make sure we have MYSQLCLI
 	path, err := exec.LookPath(MYSQLCLI)
 	if err != nil {
@@ -262,6 +459,32 @@ func (l LiveLoader) harvestMySQL(command string) (chan MyqSample, error) {
 		args = append(args, strings.Split(l.args, ` `)...)
 	}
 
+	// The extra queries come first in vertical (\G) output, parseBatch picks them out of the status
+	if command == STATUS_COMMAND {
+		for _, q := range l.loaderExtraQueries {
+			query := q.query
+			if q.fallback != "" && exec.Command(path, append(args, "-e", query)...).Run() != nil {
+				query = q.fallback
+			}
+			command = fmt.Sprint(query, `\G `, command)
+		}
+	}
+
+	// parse samples in the background, starting MYSQLCLI again if it dies
+	var ch = make(chan MyqSample)
+	go func() {
+		defer close(ch)
+		l.keepAlive(ch, errs, command, func(sessionch chan MyqSample) error {
+			return l.runMySQL(path, args, command, sessionch)
+		})
+	}()
+
+	// Got this far, the channel should start getting samples
+	return ch, nil
+}
+
+// Run MYSQLCLI with the given command every interval until it exits
+func (l LiveLoader) runMySQL(path string, args []string, command string, ch chan MyqSample) error {
 	// Initialize the command
 	cmd := exec.Command(path, args...)
 	cleanupSubcmd(cmd)
@@ -273,33 +496,24 @@ func (l LiveLoader) harvestMySQL(command string) (chan MyqSample, error) {
 	// Create a pipe for Stdout
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Create a pipe for Stdin -- we input our command here every interval
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// Handle if the subcommand exits
-	go func() {
-		err := cmd.Wait()
-		if err != nil {
-			os.Stderr.WriteString(stderr.String())
-			os.Exit(1)
-		}
-	}()
-
 	// feed the MYSQLCLI the given command to produce more output
 	full_command := strings.Join( []string{command, END_COMMAND, "\n"}, "; " )
 	send_command := func() {
-		// We don't check if the write failed, it's assumed the cmd.Wait() above will catch the sub proc dying
+		// We don't check if the write failed, it's assumed the cmd.Wait() below will catch the sub proc dying
 
 		stdin.Write([]byte(full_command)) // command we're harvesting
 	}
@@ -308,24 +522,34 @@ func (l LiveLoader) harvestMySQL(command string) (chan MyqSample, error) {
 
 	// produce more output every interval
 	ticker := time.NewTicker(l.getInterval())
+	done := make(chan bool)
 	go func() {
 		defer stdin.Close()
-		for range ticker.C {
-			send_command()
+		for {
+			select {
+			case <-ticker.C:
+				send_command()
+			case <-done:
+				return
+			}
 		}
 	}()
+	defer close(done)
+	defer ticker.Stop()
 
-	// parse samples in the background
-	var ch = make(chan MyqSample)
-	go func() {
-		defer close(ch)
-		parseSamples(stdout, ch, l.loaderInterval.getInterval())
-	}()
+	perr := parseSamples(stdout, ch, l.loaderInterval.getInterval(), SampleWindow{})
 
-	// Got this far, the channel should start getting samples
-	return ch, nil
+	// stdout is done, so the subcommand has exited (or is about to)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return perr
 }
 
-func (l LiveLoader) getStatus() (chan MyqSample, error) { return l.harvestMySQL(STATUS_COMMAND) }
+func (l LiveLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	return l.harvestMySQL(STATUS_COMMAND, errs)
+}
 
-func (l LiveLoader) getVars() (chan MyqSample, error) { return l.harvestMySQL(VARIABLES_COMMAND) }
+func (l LiveLoader) getVars(errs chan error) (chan MyqSample, error) {
+	return l.harvestMySQL(VARIABLES_COMMAND, errs)
+}
@@ -1,13 +1,19 @@
 package myqlib
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestBadFile(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "/fooey/kablooie", ""}
-	_, err := GetState(l)
+	l := NewFileLoader(1*time.Second, "/fooey/kablooie", "")
+	_, _, err := GetState(l)
 
 	if err == nil {
 		t.Error("Somehow able to open /fooey/kablooie")
@@ -15,8 +21,8 @@ func TestBadFile(t *testing.T) {
 }
 
 func TestEmpty(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "/dev/null", ""}
-	ch, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "/dev/null", "")
+	ch, err := l.getStatus(nil)
 	if err != nil {
 		t.Error("Got error opening /dev/null:", err)
 	}
@@ -35,3 +41,277 @@ func TestMetric(t *testing.T) {
 		t.Fatal("Expecting 1 KV, got", sample.Length())
 	}
 }
+
+func TestLoaderError(t *testing.T) {
+	// Opening a directory works, reading it doesn't
+	l := NewFileLoader(1*time.Second, "../testdata", "")
+	states, errs, err := GetState(l)
+	if err != nil {
+		t.Fatal("Got error opening ../testdata:", err)
+	}
+
+	if _, ok := <-states; ok {
+		t.Error("How did we get a state?")
+	}
+
+	select {
+	case err := <-errs:
+		if lerr, ok := err.(*LoaderError); !ok || lerr.Source != "../testdata" {
+			t.Error("Unexpected error:", err)
+		}
+	default:
+		t.Error("No error from reading a directory")
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	r := loaderReconnect{}
+	r.SetReconnect(1, time.Millisecond)
+
+	// The first session fails right away, the second sends a sample and fails, the third never connects
+	sessions := 0
+	ch := make(chan MyqSample)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		r.keepAlive(ch, errs, "test", func(sessionch chan MyqSample) error {
+			sessions++
+			if sessions == 2 {
+				sessionch <- MyqSample{"uptime": "1"}
+			}
+			return errors.New("connection lost")
+		})
+	}()
+
+	var got []MyqSample
+	for sample := range ch {
+		got = append(got, sample)
+	}
+
+	// gap, sample, gap
+	if len(got) != 3 || got[0] != nil || got[1] == nil || got[2] != nil {
+		t.Error("Unexpected samples:", got)
+	}
+	if sessions != 3 {
+		t.Error("Expected 3 sessions, got", sessions)
+	}
+	if err := <-errs; err == nil {
+		t.Error("No error after running out of retries")
+	}
+}
+
+func TestSendErrorFull(t *testing.T) {
+	errs := make(chan error, 1)
+	done := make(chan bool)
+	go func() {
+		sendError(errs, "first", errors.New("one"))
+		sendError(errs, "second", errors.New("two")) // nobody is reading
+		sendError(nil, "third", errors.New("three"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sendError blocked")
+	}
+	if err := <-errs; err.Error() != "first: one" {
+		t.Error("Expected the first error:", err)
+	}
+}
+
+// Loader that sends the given status samples
+type sliceLoader struct {
+	loaderInterval
+	samples []MyqSample
+}
+
+func (l sliceLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	ch := make(chan MyqSample)
+	go func() {
+		defer close(ch)
+		for _, sample := range l.samples {
+			ch <- sample
+		}
+	}()
+	return ch, nil
+}
+
+func (l sliceLoader) getVars(errs chan error) (chan MyqSample, error) {
+	return nil, errors.New("No file given")
+}
+
+func TestResetDetection(t *testing.T) {
+	l := sliceLoader{loaderInterval(1 * time.Second), []MyqSample{
+		{"uptime": "100", "uptime_since_flush_status": "100", "connections": "1000"},
+		{"uptime": "101", "uptime_since_flush_status": "101", "connections": "1010"},
+		{"uptime": "102", "uptime_since_flush_status": "1", "connections": "5"},
+		{"uptime": "103", "uptime_since_flush_status": "2", "connections": "10"},
+		{"uptime": "2", "uptime_since_flush_status": "2", "connections": "1"},
+		{"uptime": "3", "uptime_since_flush_status": "3", "connections": "3"},
+	}}
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"", "", FLUSH, "", RESTART, ""}
+	i := 0
+	for state := range states {
+		if i >= len(expected) {
+			t.Fatal("Too many states")
+		}
+		if state.Annotation != expected[i] || state.Gap != (expected[i] != "") {
+			t.Error("State", i, "unexpected annotation:", state.Annotation, state.Gap)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Error("Expected", len(expected), "states, got", i)
+	}
+
+	// The time column shows the annotation instead of the time
+	state := MyqState{Annotation: RESTART}
+	state.Cur = MyqSample{"uptime": "2"}
+	if str := <-Runtime_col.Data(&state); str != " restart" {
+		t.Error("Bad time column", str)
+	}
+}
+
+func TestCompressedFiles(t *testing.T) {
+	for _, filename := range []string{"../testdata/mysql.two.gz", "../testdata/mysqladmin.two.bz2", "../testdata/mysql.two.zst"} {
+		l := NewFileLoader(1*time.Second, filename, "")
+		samples, err := l.getStatus(nil)
+		if err != nil {
+			t.Fatal(filename, err)
+		}
+
+		i := 0
+		for sample := range samples {
+			if sample.getI(`uptime`) == 0 {
+				t.Error(filename, "sample", i, "has no uptime")
+			}
+			i++
+		}
+		if i != 2 {
+			t.Error(filename, "expected 2 samples, got", i)
+		}
+	}
+}
+
+func TestFollowFile(t *testing.T) {
+	raw, err := ioutil.ReadFile("../testdata/mysqladmin.two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Split it at the start of the second table
+	second := strings.LastIndex(string(raw), "| Variable_name")
+	second = strings.LastIndex(string(raw[:second-1]), "\n") + 1
+	first, rest := raw[:second], raw[second:]
+
+	dir, err := ioutil.TempDir("", "myq_follow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "status")
+	if err := ioutil.WriteFile(filename, first, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewFileLoader(1*time.Second, filename, "")
+	l.SetFollow(true)
+	samples, err := l.getStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(what string, uptime int64) {
+		select {
+		case sample := <-samples:
+			if sample.getI(`uptime`) != uptime {
+				t.Error(what, "expected uptime", uptime, "got", sample.getI(`uptime`))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal(what, "timed out waiting for a sample")
+		}
+	}
+	expect("first", 50683)
+
+	// Appended
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(rest)
+	f.Close()
+	expect("appended", 50684)
+
+	// Truncated
+	if err := ioutil.WriteFile(filename, first, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect("truncated", 50683)
+
+	// Rotated
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, rest, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect("rotated", 50684)
+}
+
+func TestWindowVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myq_window")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	varfile := filepath.Join(dir, "variables")
+	vars := "TS 1414170880.010 2014-10-24 13:34:40\nVariable_name\tValue\nmax_connections\t100\n" + END_STRING + "\n" +
+		"TS 1414170881.010 2014-10-24 13:34:41\nVariable_name\tValue\nmax_connections\t200\n" + END_STRING + "\n"
+	if err := ioutil.WriteFile(varfile, []byte(vars), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The variables from before the window are skipped along with the status
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.ts", varfile)
+	l.SetWindow(SampleWindow{Start: time.Unix(1414170881, 0)})
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := <-states
+	if got := state.Cur.getStr(fmt.Sprint(VAR_PREFIX, `max_connections`)); got != `200` {
+		t.Error("Expected the variables in the window, got max_connections", got)
+	}
+	if _, ok := <-states; ok {
+		t.Error("Too many states")
+	}
+
+	// Uptime offsets only apply to the status
+	l.SetWindow(SampleWindow{Skip: time.Second})
+	samples, err := l.getVars(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample := <-samples; sample.getStr(`max_connections`) != `100` {
+		t.Error("Expected the first variables, got", sample)
+	}
+}
+
+func TestSecondsDiff(t *testing.T) {
+	tests := []struct {
+		cur, prev MyqSample
+		expected  float64
+	}{
+		{MyqSample{`uptime`: `10`, TIMESTAMP_KEY: `100.9`}, MyqSample{`uptime`: `5`, TIMESTAMP_KEY: `100`}, 5},
+		{MyqSample{TIMESTAMP_KEY: `102.4`}, MyqSample{TIMESTAMP_KEY: `100.5`}, 2},
+		{MyqSample{}, MyqSample{}, 3},
+	}
+	for _, test := range tests {
+		if got := secondsDiff(test.cur, test.prev, 3*time.Second); got != test.expected {
+			t.Error(test.cur, test.prev, "expected", test.expected, "got", got)
+		}
+	}
+}
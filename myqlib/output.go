@@ -0,0 +1,163 @@
+package myqlib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Machine readable output formats (the default is the fixed width TEXT)
+const (
+	TEXT_OUTPUT string = "text"
+	JSON_OUTPUT string = "json"
+	CSV_OUTPUT  string = "csv"
+	TSV_OUTPUT  string = "tsv"
+)
+
+// The field delimiter for the CSV_OUTPUT and TSV_OUTPUT formats
+func OutputDelimiter(output string) rune {
+	if output == TSV_OUTPUT {
+		return '\t'
+	}
+	return ','
+}
+
+// A column's raw value and where it is in the view
+type colValue struct {
+	group string // title of the GroupCol it's in, "" for none
+	name  string
+	col   Col
+	value interface{}
+	time  bool // this is the view's time col
+}
+
+// The raw values of all columns in the view (including the time col), in view order
+func viewValues(v View, state *MyqState) (values []colValue) {
+	if ev, ok := v.(expandingView); ok {
+		ev.expand(state)
+	}
+	var walk func(cols []Col, group string)
+	walk = func(cols []Col, group string) {
+		for _, col := range cols {
+			switch c := col.(type) {
+			case *GroupCol:
+				walk(c.all_cols(), c.title)
+			case ValueCol:
+				values = append(values, colValue{group, c.Name(), c, c.Value(state), false})
+			default:
+				values = append(values, colValue{group, c.Name(), c, nil, false})
+			}
+		}
+	}
+	walk(v.all_cols(), "")
+	if v.time_col() != nil && len(values) > 0 {
+		values[0].time = true // all_cols puts it first
+	}
+	return
+}
+
+// NaN and Inf (from rates without a time difference) can't be written in most formats
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// Encode a raw value as JSON, numbers that JSON can't represent become null
+func jsonValue(value interface{}) ([]byte, error) {
+	if f, ok := value.(float64); ok && !finite(f) {
+		value = nil
+	}
+	return json.Marshal(value)
+}
+
+// Write one JSON object (on one line, for JSON Lines) with the raw values of the view's columns.
+// Columns in a GroupCol are nested in an object under the group's title.
+func WriteJSON(w io.Writer, v View, state *MyqState) error {
+	var buf bytes.Buffer
+	var group string
+	buf.WriteString(`{`)
+	for i, cv := range viewValues(v, state) {
+		// Close the previous group if we left it
+		if group != "" && cv.group != group {
+			buf.WriteString(`}`)
+		}
+		if i > 0 {
+			buf.WriteString(`,`)
+		}
+		// Open a new group
+		if cv.group != "" && cv.group != group {
+			key, _ := json.Marshal(cv.group)
+			buf.Write(key)
+			buf.WriteString(`:{`)
+		}
+		group = cv.group
+
+		key, _ := json.Marshal(cv.name)
+		val, err := jsonValue(cv.value)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteString(`:`)
+		buf.Write(val)
+	}
+	if group != "" {
+		buf.WriteString(`}`)
+	}
+	if state.Annotation != "" {
+		annotation, _ := json.Marshal(state.Annotation)
+		buf.WriteString(`,"annotation":`)
+		buf.Write(annotation)
+	}
+	buf.WriteString("}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Write a header row for WriteDelimited, columns in a GroupCol are named like 'Buffer Pool.dirt'
+func WriteDelimitedHeader(w io.Writer, v View, state *MyqState, comma rune) error {
+	var row []string
+	for _, cv := range viewValues(v, state) {
+		if cv.group != "" {
+			row = append(row, fmt.Sprint(cv.group, ".", cv.name))
+		} else {
+			row = append(row, cv.name)
+		}
+	}
+	return writeRow(w, row, comma)
+}
+
+// Write a row with the full precision raw values of the view's columns, missing values are empty
+func WriteDelimited(w io.Writer, v View, state *MyqState, comma rune) error {
+	var row []string
+	for _, cv := range viewValues(v, state) {
+		switch val := cv.value.(type) {
+		case nil:
+			row = append(row, "")
+		case float64:
+			if !finite(val) {
+				row = append(row, "")
+			} else {
+				row = append(row, strconv.FormatFloat(val, 'f', -1, 64))
+			}
+		case time.Time:
+			row = append(row, val.Format(time.RFC3339Nano))
+		default:
+			row = append(row, fmt.Sprint(val))
+		}
+	}
+	return writeRow(w, row, comma)
+}
+
+func writeRow(w io.Writer, row []string, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write(row)
+	cw.Flush()
+	return cw.Error()
+}
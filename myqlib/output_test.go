@@ -0,0 +1,176 @@
+package myqlib
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJSON(t *testing.T) {
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := DefaultViews()[`cttf`]
+	v.SetTimeCol(&Runtime_col)
+
+	var lines []string
+	for state := range states {
+		var buf bytes.Buffer
+		if err := WriteJSON(&buf, v, state); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, buf.String())
+	}
+	if len(lines) != 2 {
+		t.Fatal("Expected 2 lines, got", len(lines))
+	}
+
+	// Columns come out in view order, grouped by title
+	if !strings.HasPrefix(lines[1], `{"time":1,"Connects":{"cons":7,"acns":0,"acls":0},"Threads":{"conn":116,`) {
+		t.Error("Unexpected JSON:", lines[1])
+	}
+	if strings.Count(lines[1], "\n") != 1 || !strings.HasSuffix(lines[1], "}\n") {
+		t.Error("Expected one object per line:", lines[1])
+	}
+
+	var second map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	files := second[`Files`].(map[string]interface{})
+	if files[`open`] != float64(67) || files[`opns`] != float64(276) {
+		t.Error("Unexpected Files values:", files)
+	}
+
+	// The first sample has nothing to rate against, the text shows the totals ('376k') but they aren't rates
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if cons := first[`Connects`].(map[string]interface{})[`cons`]; cons != nil {
+		t.Error("Unexpected first cons:", cons)
+	}
+	if conn := first[`Threads`].(map[string]interface{})[`conn`]; conn != float64(117) {
+		t.Error("Unexpected first conn:", conn)
+	}
+}
+
+func TestWriteJSONGap(t *testing.T) {
+	samples := []MyqSample{
+		{`uptime`: `10`, `connections`: `100`},
+		{`uptime`: `5`, `connections`: `10`},
+	}
+	states, _, err := GetState(sliceLoader{loaderInterval(1 * time.Second), samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewNormalView(`test`, NewRateCol(`cons`, `Connections per second`, 4, `connections`, 0, NumberUnits),
+		NewGaugeCol(`run`, `Threads running`, 4, `threads_running`, 0, NumberUnits))
+
+	<-states
+	var buf bytes.Buffer
+	WriteJSON(&buf, v, <-states)
+	if buf.String() != `{"cons":null,"run":null,"annotation":"restart"}`+"\n" {
+		t.Error("Unexpected JSON:", buf.String())
+	}
+}
+
+func TestWriteDelimited(t *testing.T) {
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := DefaultViews()[`innodb`]
+	v.SetTimeCol(&Runtime_col)
+
+	var buf bytes.Buffer
+	first := <-states
+	if err := WriteDelimitedHeader(&buf, v, first, ','); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "time,Row Ops.read,Row Ops.dml,Buffer Pool.data,Buffer Pool.dirt,") ||
+		!strings.HasSuffix(buf.String(), ",Hist\n") {
+		t.Error("Unexpected header:", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteDelimited(&buf, v, <-states, '\t'); err != nil {
+		t.Fatal(err)
+	}
+	row := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\t")
+	if len(row) != 15 {
+		t.Fatal("Expected 15 fields, got", len(row), row)
+	}
+	// Full precision, no units
+	if row[0] != "1" || row[3] != "68411736064" || row[4] != "0.5422940211470105" {
+		t.Error("Unexpected values:", row)
+	}
+}
+
+// The exporter, sinks, JSON and CSV headers all key on the column names
+func TestUniqueColNames(t *testing.T) {
+	state := &MyqState{Cur: MyqSample{}}
+	for name, v := range DefaultViews() {
+		v.SetTimeCol(&Timestamp_col)
+
+		var buf bytes.Buffer
+		if err := WriteDelimitedHeader(&buf, v, state, ','); err != nil {
+			t.Fatal(err)
+		}
+		seen := map[string]bool{}
+		for _, col := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), ",") {
+			if seen[col] {
+				t.Error(name, "has more than one", col, "column")
+			}
+			seen[col] = true
+		}
+
+		buf.Reset()
+		if err := WriteJSON(&buf, v, state); err != nil {
+			t.Fatal(err)
+		}
+		if dup := duplicateJSONKey(t, buf.Bytes()); dup != "" {
+			t.Error(name, "has more than one", dup, "JSON key:", buf.String())
+		}
+	}
+}
+
+// The first key repeated in a JSON object (nested ones too), json.Unmarshal just keeps the last
+func duplicateJSONKey(t *testing.T, data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var objects []map[string]bool
+	expectKey := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return ""
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch tok {
+		case json.Delim('{'):
+			objects = append(objects, map[string]bool{})
+			expectKey = true
+			continue
+		case json.Delim('}'):
+			objects = objects[:len(objects)-1]
+			expectKey = len(objects) > 0
+			continue
+		}
+		if key, ok := tok.(string); ok && expectKey {
+			if objects[len(objects)-1][key] {
+				return key
+			}
+			objects[len(objects)-1][key] = true
+			expectKey = false // the value is next
+			continue
+		}
+		expectKey = len(objects) > 0 // after a value comes another key
+	}
+}
@@ -2,8 +2,8 @@ package myqlib
 
 import (
 	"bytes"
+	"errors"
 	"io"
-	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -17,8 +17,22 @@ const (
 	TABULAR
 )
 
-// Parse lines from mysql SHOW output.
-func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration) {
+// Which samples to parse, the zero value keeps them all.  Offsets are by uptime from the first sample.
+type SampleWindow struct {
+	Skip       time.Duration // skip samples before this offset
+	Until      time.Duration // stop after this offset (0 for no limit)
+	Start, End time.Time     // only samples with a timestamp in this range (zero for no limit)
+}
+
+func (w SampleWindow) isZero() bool {
+	return w.Skip == 0 && w.Until == 0 && w.Start.IsZero() && w.End.IsZero()
+}
+
+// Returned by the split function once we're past the end of the SampleWindow
+var errWindowEnd = errors.New("Past the end of the sample window")
+
+// Parse lines from mysql SHOW output.  Returns any error reading from the reader.
+func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration, window SampleWindow) error {
 	outputtype := BATCH // default to BATCH
 	typechecked := false
 	recordmatch := []byte(END_STRING)
@@ -27,41 +41,134 @@ func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration) {
 	// if the interval is larger, we check samples for intervals
 	// so we can avoid parsing them fully.
 	check_intervals := false
-	uptime_str := []byte(`Uptime`)
 	var prev_uptime float64
 	if interval.Nanoseconds() > 1000000 {
 		check_intervals = true
 	}
 	// Scan back for the Uptime in the given record and return true if it can be skipped
 	skip_interval := func(record []byte) (skippable bool) {
-		upt_pos := bytes.Index(record, uptime_str) + len(uptime_str) // After the Uptime
-		if upt_pos >= 0 {
-			upt_nl := bytes.IndexByte(record[upt_pos:], '\n') + upt_pos    // Find the next newline
-			uptime_str := string(bytes.Trim(record[upt_pos:upt_nl], `| `)) // Trim extra chars
-			current_uptime, _ := strconv.ParseFloat(uptime_str, 64)        // Parse the str to float
-			if prev_uptime == 0 {
+		if current_uptime, ok := recordUptime(record); ok {
+			if prev_uptime == 0 || current_uptime < prev_uptime {
+				// First sample, or the server restarted: keep it as a new baseline
 				prev_uptime = current_uptime
+			} else if current_uptime-prev_uptime < interval.Seconds() {
+				// This sample's uptime is too early, skip it
+				return true
 			} else {
-				if current_uptime-prev_uptime < interval.Seconds() {
-					// This sample's uptime is too early, skip it
-					return true
-				}
+				prev_uptime = current_uptime
 			}
 		}
 		return false
 	}
 
+	// Check the record's offset and timestamp against the window, also without parsing it fully
+	var offset, window_uptime float64
+	var window_ts string // the timestamp that applies to the next record
+	skip_window := func(record []byte) (skippable bool, err error) {
+		// Timestamps at the start of a record are its own, otherwise it came before the record
+		ts := window_ts
+		if bytes.HasPrefix(record, ts_prefix) {
+			ts = lastTimestamp(record[:bytes.IndexByte(record, '\n')+1])
+		}
+		if last := lastTimestamp(record); last != "" {
+			window_ts = last
+		}
+
+		if uptime, ok := recordUptime(record); ok {
+			// Count the offset across restarts too
+			if window_uptime > 0 {
+				if uptime >= window_uptime {
+					offset += uptime - window_uptime
+				} else {
+					offset += uptime
+				}
+			}
+			window_uptime = uptime
+
+			if window.Until > 0 && offset > window.Until.Seconds() {
+				return true, errWindowEnd
+			}
+			if offset < window.Skip.Seconds() {
+				return true, nil
+			}
+		} else if ts == "" {
+			return false, nil // not a status sample, and variables without a timestamp can't be placed in the window
+		}
+
+		if !window.Start.IsZero() || !window.End.IsZero() {
+			epoch, err := strconv.ParseFloat(ts, 64)
+			if err != nil {
+				return true, nil // can't be in the range without a timestamp
+			}
+			if !window.End.IsZero() && epoch > float64(window.End.Unix()) {
+				return true, errWindowEnd
+			}
+			if !window.Start.IsZero() && epoch < float64(window.Start.Unix()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// Timestamp from the last record we skipped
+	var skipped_ts string
+
+	// Decide if a record is worth parsing
+	skip_record := func(record []byte) (skippable bool, err error) {
+		if !window.isZero() {
+			skippable, err = skip_window(record)
+		}
+		if !skippable && check_intervals {
+			skippable = skip_interval(record)
+		}
+
+		// Keep the last timestamp in case it was a preamble for the next sample
+		if skippable {
+			if ts := lastTimestamp(record); ts != "" {
+				skipped_ts = ts
+			}
+		}
+		return
+	}
+
 	// This scanner will look for the start of a new set of SHOW STATUS output
 	scanner := NewScanner(reader)
 	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		// Check if this looks like a TABULAR file, but only once
 		if !typechecked {
-			if bytes.HasPrefix(data, []byte(`+`)) || bytes.HasPrefix(data, []byte(`|`)) {
+			// Look past any timestamp preamble
+			start := data
+			for bytes.HasPrefix(start, ts_prefix) {
+				nl := bytes.IndexByte(start, '\n')
+				if nl < 0 {
+					break
+				}
+				start = start[nl+1:]
+			}
+			if len(start) == 0 && !atEOF {
+				return 0, nil, nil // need more data to tell
+			}
+
+			if bytes.HasPrefix(start, []byte(`+`)) || bytes.HasPrefix(start, []byte(`|`)) {
 				outputtype, recordmatch = TABULAR, []byte(`| Variable_name`)
 			}
 			typechecked = true
 		}
 
+		// A TABULAR record is complete at the border after its last row, so we don't have to wait
+		// for the next one to start (that may be a while if the file is being followed)
+		if outputtype == TABULAR {
+			if tend := bytes.Index(data, table_end); tend >= 0 {
+				if end := bytes.Index(data, recordmatch); end < 0 || tend < end {
+					tend += len(table_end) - 1 // keep the border for the next record
+					if skippable, err := skip_record(data[0:tend]); skippable {
+						return tend, nil, err
+					}
+					return tend, data[0:tend], nil
+				}
+			}
+		}
+
 		// Find a new record
 		if end := bytes.Index(data, recordmatch); end >= 0 {
 			nl := bytes.IndexByte(data[end:], '\n') // Find the subsequent newline
@@ -71,9 +178,9 @@ func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration) {
 				return end + nl + 1, nil, nil
 			}
 
-			// If we are checking interval, see if we should skip this record
-			if check_intervals && skip_interval(data[0:end]) {
-				return end + nl + 1, nil, nil
+			// See if we should skip this record
+			if skippable, err := skip_record(data[0:end]); skippable {
+				return end + nl + 1, nil, err
 			}
 			// fmt.Println( "Found record: ", string(data[0:end]))
 			return end + nl + 1, data[0:end], nil
@@ -81,6 +188,9 @@ func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration) {
 
 		// if we're at EOF and have data, return it, otherwise let it fall through
 		if atEOF && len(data) > 0 {
+			if skippable, err := skip_record(data); skippable {
+				return len(data), nil, err
+			}
 			return len(data), data, nil
 		}
 
@@ -88,20 +198,70 @@ func parseSamples(reader io.Reader, ch chan MyqSample, interval time.Duration) {
 		return 0, nil, nil
 	})
 
+	// Timestamps that showed up after the previous sample belong to the next one
+	var next_ts string
 	for scanner.Scan() {
+		if skipped_ts != "" {
+			next_ts, skipped_ts = skipped_ts, ""
+		}
+
 		// The scanner sends complete samples
-		parseBatch(ch, bytes.NewBuffer(scanner.Bytes()), outputtype)
+		next_ts = parseBatch(ch, bytes.NewBuffer(scanner.Bytes()), outputtype, next_ts)
 	}
 
-	// Not sure if we care here or not, remains to be seen
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+	// Let the loader decide what to do with it
+	if err := scanner.Err(); err != errWindowEnd {
+		return err
 	}
+	return nil
 }
 
-// Parse a full sample into individual lines, populate a MyqSample and emit it to the channel
-func parseBatch(ch chan MyqSample, buffer *bytes.Buffer, outputtype showoutputtype) {
+// Rows of vertical output are threads, statement digests, wait classes or replication channels
+func addVerticalRow(sample MyqSample, row map[string]string) {
+	switch {
+	case isProcessRow(row):
+		addProcess(sample, captureRow(row))
+	case isDigestRow(row):
+		addDigest(sample, captureRow(row))
+	case isWaitRow(row):
+		addWait(sample, captureRow(row))
+	default:
+		addReplication(sample, row)
+	}
+}
+
+// The last row of a TABULAR table followed by its bottom border
+var table_end = []byte("|\n+")
+
+var (
+	uptime_str   = []byte(`Uptime`)
+	uptime_lower = []byte(`uptime`) // samples written by a RecordingLoader have lowercase keys
+)
+
+// Find the Uptime in a record without parsing the whole thing
+func recordUptime(record []byte) (float64, bool) {
+	upt_pos := bytes.Index(record, uptime_str)
+	if upt_pos < 0 {
+		upt_pos = bytes.Index(record, uptime_lower)
+	}
+	if upt_pos < 0 {
+		return 0, false
+	}
+	upt_pos += len(uptime_str)                        // After the Uptime
+	upt_nl := bytes.IndexByte(record[upt_pos:], '\n') // Find the next newline
+	if upt_nl < 0 {
+		upt_nl = len(record) - upt_pos
+	}
+	uptime_str := string(bytes.Trim(record[upt_pos:upt_pos+upt_nl], "| \t")) // Trim extra chars
+	uptime, err := strconv.ParseFloat(uptime_str, 64)                        // Parse the str to float
+	return uptime, err == nil
+}
+
+// Parse a full sample into individual lines, populate a MyqSample and emit it to the channel.
+// ts is a timestamp from before this record, any timestamp after the data is returned for the next one.
+func parseBatch(ch chan MyqSample, buffer *bytes.Buffer, outputtype showoutputtype, ts string) (next_ts string) {
 	var divideridx int
+	var vertical verticalRows // SHOW SLAVE STATUS\G, SHOW ENGINE INNODB STATUS\G and other multi-row results in BATCH output
 
 	timesample := make(MyqSample)
 	scanner := NewScanner(buffer)
@@ -110,6 +270,16 @@ func parseBatch(ch chan MyqSample, buffer *bytes.Buffer, outputtype showoutputty
 		line := scanner.Bytes()
 		var key, value []byte
 
+		// Timestamps before the data are ours, after are (a preamble) for the next sample
+		if linets := parseTimestampLine(line); linets != "" {
+			if timesample.Length() == 0 {
+				ts = linets
+			} else {
+				next_ts = linets
+			}
+			continue
+		}
+
 		switch outputtype {
 		case TABULAR:
 			// Line here looks like this: (value can contain spaces)
@@ -130,6 +300,9 @@ func parseBatch(ch chan MyqSample, buffer *bytes.Buffer, outputtype showoutputty
 			key = bytes.Trim(line[:divideridx], `| `)
 			value = bytes.Trim(line[divideridx:], `| `)
 		case BATCH:
+			if vertical.parseLine(string(line)) {
+				continue
+			}
 			// Batch is much easier, just split on the tab
 			raw := bytes.Split(line, []byte("\t"))
 			// If we don't get 2 fields, skip it.
@@ -141,8 +314,55 @@ func parseBatch(ch chan MyqSample, buffer *bytes.Buffer, outputtype showoutputty
 
 		timesample[strings.ToLower(string(key))] = string(value)
 	}
+	for _, row := range vertical.rows {
+		addVerticalRow(timesample, row)
+	}
+	if len(vertical.monitor) > 0 {
+		addInnodbStatus(timesample, strings.Join(vertical.monitor, "\n"))
+	}
 
 	if timesample.Length() > 0 {
+		if ts != "" {
+			timesample[TIMESTAMP_KEY] = ts
+		}
 		ch <- timesample
+		return next_ts
+	}
+
+	// Nothing here, so whatever timestamp we have is still for the next sample
+	if next_ts != "" {
+		return next_ts
+	}
+	return ts
+}
+
+// Timestamp marker lines look like pt-stalk's: 'TS 1414170880.005 2014-10-24 13:34:40'
+var ts_prefix = []byte(`TS `)
+
+// Return the epoch of a timestamp marker line, or "" if it isn't one
+func parseTimestampLine(line []byte) string {
+	if !bytes.HasPrefix(line, ts_prefix) {
+		return ""
+	}
+	fields := bytes.Fields(line)
+	if len(fields) < 2 {
+		return ""
+	}
+	if _, err := strconv.ParseFloat(string(fields[1]), 64); err != nil {
+		return ""
+	}
+	return string(fields[1])
+}
+
+// Find the last timestamp marker in a record without scanning every line
+func lastTimestamp(record []byte) string {
+	pos := bytes.LastIndex(record, []byte("\nTS ")) + 1 // 0 if not found
+	if pos == 0 && !bytes.HasPrefix(record, ts_prefix) {
+		return ""
+	}
+	line := record[pos:]
+	if nl := bytes.IndexByte(line, '\n'); nl >= 0 {
+		line = line[:nl]
 	}
+	return parseTimestampLine(line)
 }
@@ -1,14 +1,15 @@
 package myqlib
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
-	// "fmt"
 )
 
 func TestSingleSample(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.single", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.single", "")
+	samples, err := l.getStatus(nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -53,8 +54,8 @@ func TestSingleSample(t *testing.T) {
 }
 
 func TestTwoSamples(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.two", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	samples, err := l.getStatus(nil)
 
 	if err != nil {
 		t.Error(err)
@@ -68,8 +69,8 @@ func TestManySamples(t *testing.T) {
 		return
 	}
 
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.lots", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.lots", "")
+	samples, err := l.getStatus(nil)
 
 	if err != nil {
 		t.Error(err)
@@ -79,8 +80,8 @@ func TestManySamples(t *testing.T) {
 }
 
 func TestSingleBatchSample(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.single", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.single", "")
+	samples, err := l.getStatus(nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -125,8 +126,8 @@ func TestSingleBatchSample(t *testing.T) {
 }
 
 func TestTwoBatchSamples(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.two", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.two", "")
+	samples, err := l.getStatus(nil)
 
 	if err != nil {
 		t.Error(err)
@@ -140,8 +141,8 @@ func TestManyBatchSamples(t *testing.T) {
 		return
 	}
 
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.lots", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.lots", "")
+	samples, err := l.getStatus(nil)
 
 	if err != nil {
 		t.Error(err)
@@ -183,8 +184,8 @@ func checksamples(t *testing.T, samples chan MyqSample, expected int) {
 }
 
 func TestTokuSample(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.toku", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.toku", "")
+	samples, err := l.getStatus(nil)
 
 	if err != nil {
 		t.Error(err)
@@ -193,11 +194,89 @@ func TestTokuSample(t *testing.T) {
 	checksamples(t, samples, 2)
 }
 
+func TestTimestampSamples(t *testing.T) {
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.ts", "")
+	samples, err := l.getStatus(nil)
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	// The TS preamble before each table belongs to the sample after it
+	for _, expected := range []string{"1414170880.005", "1414170881.007"} {
+		sample := <-samples
+		if sample.getStr(TIMESTAMP_KEY) != expected {
+			t.Error("Expected timestamp", expected, "got", sample.getStr(TIMESTAMP_KEY))
+		}
+		if sample.getStr(`uptime`) == "" {
+			t.Error("Missing uptime, sample was not parsed as TABULAR")
+		}
+	}
+	if _, ok := <-samples; ok {
+		t.Error("Too many samples")
+	}
+
+	// The capture time column shows the (local) time of the timestamp
+	state := MyqState{Cur: MyqSample{TIMESTAMP_KEY: "1414170881.5"}}
+	ts, err := state.Cur.getTimestamp()
+	if err != nil || ts.Unix() != 1414170881 || ts.Nanosecond() != 500000000 {
+		t.Error("Bad timestamp", ts, err)
+	}
+	if str := <-Capturetime_col.Data(&state); str != ts.Format(`15:04:05`) {
+		t.Error("Bad time column", str)
+	}
+}
+
+func TestNoTimestampSamples(t *testing.T) {
+	// 'mysqladmin ext -i' writes no timestamps, so the time column counts from the first sample
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-states
+	state := <-states
+	if ts := state.Cur.getStr(TIMESTAMP_KEY); ts != "" {
+		t.Error("Unexpected timestamp", ts)
+	}
+	expected := fmt.Sprintf("%.0fs", Runtime_col.(ValueCol).Value(state))
+	if str := <-Capturetime_col.Data(state); strings.TrimSpace(str) != expected {
+		t.Error("Expected the time since the first sample", expected, "got", str)
+	}
+}
+
+func TestWindowSamples(t *testing.T) {
+	// Uptime offsets: mysql.lots starts at an uptime of 5665
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.lots", "")
+	l.SetWindow(SampleWindow{Skip: 10 * time.Second, Until: 20 * time.Second})
+	samples, err := l.getStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uptimes []int64
+	for sample := range samples {
+		uptimes = append(uptimes, sample.getI(`uptime`))
+	}
+	if len(uptimes) != 10 || uptimes[0] != 5675 || uptimes[len(uptimes)-1] != 5685 {
+		t.Error("Unexpected samples in window:", uptimes)
+	}
+
+	// Wall clock range: only the second sample of mysqladmin.ts
+	l = NewFileLoader(1*time.Second, "../testdata/mysqladmin.ts", "")
+	l.SetWindow(SampleWindow{Start: time.Unix(1414170881, 0), End: time.Unix(1414170890, 0)})
+	samples, err = l.getStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksamples(t, samples, 1)
+}
 
 func BenchmarkParseStatus(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.single", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.single", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -208,8 +287,8 @@ func BenchmarkParseStatus(b *testing.B) {
 
 func BenchmarkParseStatusBatch(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.single", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/mysql.single", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -220,8 +299,8 @@ func BenchmarkParseStatusBatch(b *testing.B) {
 
 func BenchmarkParseVariablesBatch(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/variables", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/variables", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -232,8 +311,8 @@ func BenchmarkParseVariablesBatch(b *testing.B) {
 
 func BenchmarkParseVariablesTabular(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/variables.tab", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/variables.tab", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -244,8 +323,8 @@ func BenchmarkParseVariablesTabular(b *testing.B) {
 
 func BenchmarkParseManyBatchSamples(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysql.lots", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/mysql.lots", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -258,8 +337,8 @@ func BenchmarkParseManyBatchSamples(b *testing.B) {
 
 func BenchmarkParseManySamples(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.lots", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.lots", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -272,8 +351,8 @@ func BenchmarkParseManySamples(b *testing.B) {
 
 func BenchmarkParseManySamplesLongInterval(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		l := FileLoader{loaderInterval(1 * time.Minute), "../testdata/mysqladmin.lots", ""}
-		samples, err := l.getStatus()
+		l := NewFileLoader(1*time.Minute, "../testdata/mysqladmin.lots", "")
+		samples, err := l.getStatus(nil)
 
 		if err != nil {
 			b.Error(err)
@@ -0,0 +1,254 @@
+package myqlib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PROCESSLIST_COMMAND string = "SHOW FULL PROCESSLIST"
+
+	// prefix of processlist keys in a sample: proc_<field>.<id>, one for every column of every thread
+	PROCESS_PREFIX = "proc_"
+)
+
+// Where live loaders can get the processlist from, all with the SHOW FULL PROCESSLIST column names.
+// performance_schema.threads doesn't take a mutex for every thread like the others (before 8.0.22).
+var ProcesslistSources = map[string]string{
+	`processlist`:        PROCESSLIST_COMMAND,
+	`information_schema`: `SELECT ID AS Id, USER AS User, HOST AS Host, DB AS db, COMMAND AS Command, TIME AS Time, STATE AS State, INFO AS Info FROM information_schema.PROCESSLIST`,
+	`performance_schema`: `SELECT PROCESSLIST_ID AS Id, PROCESSLIST_USER AS User, PROCESSLIST_HOST AS Host, PROCESSLIST_DB AS db, PROCESSLIST_COMMAND AS Command, PROCESSLIST_TIME AS Time, PROCESSLIST_STATE AS State, PROCESSLIST_INFO AS Info FROM performance_schema.threads WHERE PROCESSLIST_ID IS NOT NULL`,
+}
+
+// The processlist for live loaders to collect too, with one of the ProcesslistSources queries
+func ProcesslistQuery(query string) ExtraQuery {
+	return ExtraQuery{query: query, add: addProcess}
+}
+
+// One thread in the processlist
+type Process struct {
+	Id, User, Host, Db, Command, State, Info string
+	Time                                     float64 // seconds in the current state
+}
+
+// Not doing anything (yet)
+func (p Process) Idle() bool {
+	return p.Command == `Sleep` || p.Command == `Daemon` || p.Command == `Connect` || p.Command == `Binlog Dump` || p.Command == `Binlog Dump GTID`
+}
+
+// The key of a processlist field in a sample
+func processKey(field, id string) string {
+	return fmt.Sprint(PROCESS_PREFIX, field, `.`, id)
+}
+
+// Rows from performance_schema.threads may not be aliased, so drop processlist_ from the field names
+func processField(field string) string {
+	return strings.TrimPrefix(strings.ToLower(field), `processlist_`)
+}
+
+// Whether a vertical row is a thread (and not a replication channel)
+func isProcessRow(row map[string]string) bool {
+	for name := range row {
+		if processField(name) == `command` {
+			return true
+		}
+	}
+	return false
+}
+
+// Add one processlist row to a sample
+func addProcess(sample MyqSample, row map[string]string) {
+	fields := map[string]string{}
+	for name, value := range row {
+		fields[processField(name)] = value
+	}
+	id := fields[`id`]
+	if id == "" {
+		return
+	}
+	for field, value := range fields {
+		sample[processKey(field, id)] = value
+	}
+}
+
+// The threads in a sample, by id
+func Processes(sample MyqSample) (procs []Process) {
+	command := fmt.Sprint(PROCESS_PREFIX, `command.`)
+	for key, value := range sample {
+		if !strings.HasPrefix(key, command) {
+			continue
+		}
+		id := strings.TrimPrefix(key, command)
+		procs = append(procs, Process{
+			Id:      id,
+			User:    sample.getStr(processKey(`user`, id)),
+			Host:    sample.getStr(processKey(`host`, id)),
+			Db:      sample.getStr(processKey(`db`, id)),
+			Command: value,
+			State:   sample.getStr(processKey(`state`, id)),
+			Info:    sample.getStr(processKey(`info`, id)),
+			Time:    sample.getF(processKey(`time`, id)),
+		})
+	}
+	sort.Slice(procs, func(i, j int) bool {
+		a, _ := strconv.ParseUint(procs[i].Id, 10, 64)
+		b, _ := strconv.ParseUint(procs[j].Id, 10, 64)
+		return a < b
+	})
+	return
+}
+
+// Undo the mysql cli's batch escaping
+var batchUnescaper = strings.NewReplacer(`\\`, "\\", `\t`, "\t", `\n`, "\n", `\0`, "\x00")
+
+// The mysql cli prints NULL (like for db, state and info of idle threads), samples use "" like SqlLoader
+func captureValue(value string) string {
+	if value == `NULL` {
+		return ""
+	}
+	return value
+}
+
+// A row from mysql cli output with its NULLs as ""
+func captureRow(row map[string]string) map[string]string {
+	for name, value := range row {
+		row[name] = captureValue(value)
+	}
+	return row
+}
+
+// Parse SHOW FULL PROCESSLIST captures into samples: tabular (mysql -t or mysqladmin processlist),
+// batch with column names (mysql -B) or vertical (\G, like pt-stalk's processlist files).  A sample
+// starts at every header (or first row), and TS lines before one are its timestamp.
+func parseProcesslists(reader io.Reader, ch chan MyqSample) error {
+	var sample MyqSample
+	var ts string        // for the next sample
+	var columns []string // from the last header
+	var borders []int    // of the TABULAR columns, from the last +---+ line
+	var vertical verticalRows
+
+	send := func() {
+		if sample != nil {
+			for _, row := range vertical.rows {
+				addProcess(sample, captureRow(row))
+			}
+			ch <- sample
+		}
+		sample, vertical = nil, verticalRows{}
+	}
+	start := func() {
+		send()
+		sample = MyqSample{}
+		if ts != "" {
+			sample[TIMESTAMP_KEY], ts = ts, ""
+		}
+	}
+	addRow := func(values []string) {
+		if sample == nil || len(values) != len(columns) {
+			return // not a row of this table (like a multi-line Info)
+		}
+		row := map[string]string{}
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		addProcess(sample, row)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Info can be a long query
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if linets := parseTimestampLine([]byte(line)); linets != "" {
+			send()
+			ts = linets
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, `***`) && strings.Contains(line, `. row *`):
+			if strings.Contains(line, ` 1. row *`) || sample == nil {
+				start()
+			}
+			vertical.parseLine(line)
+		case vertical.open && vertical.parseLine(line):
+			// the next field of a \G row, or more of a multi-line Info (which can have tabs, | and +)
+		case strings.HasPrefix(line, `+`):
+			borders = borders[:0]
+			for i, c := range line {
+				if c == '+' {
+					borders = append(borders, i)
+				}
+			}
+		case strings.HasPrefix(line, `|`) && len(borders) > 1:
+			var values []string
+			for i := 1; i < len(borders); i++ {
+				if borders[i] > len(line) {
+					break
+				}
+				values = append(values, strings.TrimSpace(line[borders[i-1]+1:borders[i]]))
+			}
+			if len(values) > 0 && strings.EqualFold(values[0], `Id`) {
+				start()
+				columns = values
+				continue
+			}
+			for i := range values {
+				values[i] = captureValue(values[i])
+			}
+			addRow(values)
+		case strings.Contains(line, "\t"):
+			values := strings.Split(line, "\t")
+			if strings.EqualFold(values[0], `Id`) {
+				start()
+				columns = values
+				continue
+			}
+			for i := range values {
+				values[i] = captureValue(batchUnescaper.Replace(values[i]))
+			}
+			addRow(values)
+		default:
+			vertical.parseLine(line)
+		}
+	}
+	send()
+	return scanner.Err()
+}
+
+// Load SHOW FULL PROCESSLIST captures instead of SHOW STATUS output
+type ProcesslistFileLoader struct {
+	loaderInterval
+	file string
+}
+
+func NewProcesslistFileLoader(i time.Duration, file string) *ProcesslistFileLoader {
+	return &ProcesslistFileLoader{loaderInterval(i), file}
+}
+
+func (l ProcesslistFileLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	file, err := openCapture(l.file)
+	if err != nil {
+		return nil, err
+	}
+
+	var ch = make(chan MyqSample)
+	go func() {
+		defer file.Close()
+		defer close(ch)
+		if err := parseProcesslists(file, ch); err != nil {
+			sendError(errs, l.file, err)
+		}
+	}()
+	return ch, nil
+}
+
+func (l ProcesslistFileLoader) getVars(errs chan error) (chan MyqSample, error) {
+	return nil, errors.New("No file given")
+}
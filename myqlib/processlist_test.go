@@ -0,0 +1,110 @@
+package myqlib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func processlistStates(t *testing.T, file string) []*MyqState {
+	l := NewProcesslistFileLoader(1*time.Second, file)
+	ch, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []*MyqState
+	for state := range ch {
+		states = append(states, state)
+	}
+	return states
+}
+
+func TestProcesslistCaptures(t *testing.T) {
+	for _, file := range []string{
+		"../testdata/processlist.tabular",
+		"../testdata/processlist.batch",
+		"../testdata/pt-stalk/2014_10_24_13_34_40-processlist",
+	} {
+		states := processlistStates(t, file)
+		if len(states) != 2 {
+			t.Error(file, "expected 2 states, got", len(states))
+			continue
+		}
+		if states[1].SecondsDiff != 1 {
+			t.Error(file, "unexpected SecondsDiff:", states[1].SecondsDiff)
+		}
+
+		var ids []string
+		for _, p := range Processes(states[1].Cur) {
+			ids = append(ids, p.Id)
+		}
+		if ids[len(ids)-2] != `815` || ids[len(ids)-1] != `901` {
+			t.Error(file, "unexpected threads:", ids)
+		}
+		first := Processes(states[0].Cur)
+		p := first[len(first)-2]
+		if p.User != `app` || p.Host != `10.0.0.6:51240` || p.Db != `shop` || p.Command != `Query` || p.Time != 42 || p.State != `updating` {
+			t.Errorf("%s unexpected thread: %+v", file, p)
+		}
+		if root := first[len(first)-1]; root.Db != "" || root.Idle() {
+			t.Errorf("%s expected NULL db for root: %+v", file, root)
+		}
+	}
+}
+
+func TestProcesslistMultiLineInfo(t *testing.T) {
+	sample := processlistStates(t, "../testdata/pt-stalk/2014_10_24_13_34_40-processlist")[0].Cur
+	procs := Processes(sample)
+	if p := procs[0]; p.Id != `5` || p.Time != 300 || p.Info != "SELECT *\nfrom t where ts > '2014-10-24 13:00:00'\n\n\tAND note = 'Id: 7'" {
+		t.Errorf("Unexpected multi-line query: %+v", p)
+	}
+	if len(procs) != 4 {
+		t.Errorf("Expected 4 threads, got %+v", procs)
+	}
+	for key := range sample {
+		if !strings.HasPrefix(key, PROCESS_PREFIX) && key != TIMESTAMP_KEY {
+			t.Error("Part of a query became a key:", key)
+		}
+	}
+}
+
+func TestProcesslistBatchInfo(t *testing.T) {
+	sample := processlistStates(t, "../testdata/processlist.batch")[0].Cur
+	if info := sample.getStr(processKey(`info`, `815`)); info != "UPDATE orders\n   SET state = \t'shipped'" {
+		t.Errorf("Expected the batch escapes undone: %q", info)
+	}
+	if ts, _ := sample.getTimestamp(); ts.Unix() != 1414157680 {
+		t.Error("Unexpected timestamp:", ts)
+	}
+}
+
+// The processlist from a live server, in vertical output with replication status
+func TestProcesslistBatch(t *testing.T) {
+	buffer := bytes.NewBufferString(`*************************** 1. row ***************************
+     Id: 12
+   User: app
+   Host: 10.0.0.5:40022
+     db: NULL
+Command: Sleep
+   Time: 5
+  State:
+   Info: NULL
+*************************** 1. row ***************************
+     Slave_IO_Running: Yes
+Seconds_Behind_Master: 0
+Uptime	100
+`)
+	ch := make(chan MyqSample, 1)
+	parseBatch(ch, buffer, BATCH, "")
+	sample := <-ch
+
+	expected := []Process{{Id: `12`, User: `app`, Host: `10.0.0.5:40022`, Command: `Sleep`, Time: 5}}
+	if procs := Processes(sample); !reflect.DeepEqual(procs, expected) {
+		t.Errorf("Unexpected threads: %+v", procs)
+	}
+	if sample.getStr(`repl_slave_io_running`) != `Yes` || sample.getStr(`repl_id`) != `` {
+		t.Error("Unexpected replication status:", sample)
+	}
+}
@@ -0,0 +1,227 @@
+package myqlib
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// What processlist summaries can count threads by
+var ProcessGroupings = []string{`user`, `host`, `db`, `command`, `state`}
+
+// How the groups in a summary are sorted
+const (
+	SORT_COUNT  string = "count"  // most threads first
+	SORT_ACTIVE string = "active" // most active (not idle) threads first
+	SORT_TIME   string = "time"   // longest running active thread first
+	SORT_NAME   string = "name"
+)
+
+// Width of the names in a summary
+const TOP_NAME_WIDTH = 20
+
+// The threads with the same user, host, db, command or state
+type ProcessGroup struct {
+	Name            string
+	Threads, Active int
+	Longest         float64 // seconds, of the active threads
+}
+
+// The value of a thread to group it by
+func groupName(p Process, by string) string {
+	var name string
+	switch by {
+	case `user`:
+		name = p.User
+	case `host`:
+		name = p.Host
+		if colon := strings.LastIndex(name, `:`); colon > 0 && strings.Trim(name[colon+1:], `0123456789`) == "" {
+			name = name[:colon] // every connection has its own port
+		}
+	case `db`:
+		name = p.Db
+	case `command`:
+		name = p.Command
+	case `state`:
+		name = p.State
+	}
+	if name == "" {
+		return `-`
+	}
+	return name
+}
+
+// Count the threads by one of the ProcessGroupings, sorted by sortby
+func groupProcesses(procs []Process, by, sortby string) (groups []ProcessGroup) {
+	index := map[string]int{}
+	for _, p := range procs {
+		name := groupName(p, by)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, ProcessGroup{Name: name})
+		}
+		groups[i].Threads++
+		if !p.Idle() {
+			groups[i].Active++
+			if p.Time > groups[i].Longest {
+				groups[i].Longest = p.Time
+			}
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		switch {
+		case sortby == SORT_COUNT && a.Threads != b.Threads:
+			return a.Threads > b.Threads
+		case sortby == SORT_ACTIVE && a.Active != b.Active:
+			return a.Active > b.Active
+		case sortby == SORT_TIME && a.Longest != b.Longest:
+			return a.Longest > b.Longest
+		}
+		return a.Name < b.Name
+	})
+	return
+}
+
+// The active threads, longest running first
+func longestProcesses(procs []Process) (active []Process) {
+	for _, p := range procs {
+		if !p.Idle() {
+			active = append(active, p)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Time > active[j].Time
+	})
+	return
+}
+
+// A top-like summary of the processlist: thread counts by user, host, etc. and the longest running queries
+type ProcessTop struct {
+	groupings []string
+	sortby    string
+	limit     int   // rows in each table
+	width     int64 // of the screen
+}
+
+func NewProcessTop(groupings []string, sortby string, limit int) (*ProcessTop, error) {
+	for _, by := range groupings {
+		found := false
+		for _, known := range ProcessGroupings {
+			found = found || by == known
+		}
+		if !found {
+			return nil, fmt.Errorf("can't group threads by '%s', only by %s", by, strings.Join(ProcessGroupings, `, `))
+		}
+	}
+	switch sortby {
+	case SORT_COUNT, SORT_ACTIVE, SORT_TIME, SORT_NAME:
+	default:
+		return nil, fmt.Errorf("can't sort by '%s', only by %s, %s, %s or %s", sortby, SORT_COUNT, SORT_ACTIVE, SORT_TIME, SORT_NAME)
+	}
+	return &ProcessTop{groupings, sortby, limit, 80}, nil
+}
+
+func (t *ProcessTop) SetWidth(w int64) {
+	t.width = w
+}
+
+// One table of groups, every line the same width
+func (t *ProcessTop) groupTable(procs []Process, by string) (lines []string) {
+	lines = append(lines, fmt.Sprintf("%-*s %4s %4s %5s", TOP_NAME_WIDTH, by, `thds`, `actv`, `long`))
+	for i, g := range groupProcesses(procs, by, t.sortby) {
+		if i == t.limit {
+			break
+		}
+		long := `-`
+		if g.Active > 0 {
+			long = process_time(g.Longest)
+		}
+		lines = append(lines, fmt.Sprintf("%-*.*s %4d %4d %5s", TOP_NAME_WIDTH, TOP_NAME_WIDTH, g.Name, g.Threads, g.Active, long))
+	}
+	return
+}
+
+// Write the summary of a state's processlist
+func (t *ProcessTop) Render(w io.Writer, state *MyqState) error {
+	procs := Processes(state.Cur)
+	longest := longestProcesses(procs)
+
+	var lines []string
+	title := fmt.Sprint(len(procs), ` threads, `, len(longest), ` active`)
+	if len(longest) > 0 {
+		title = fmt.Sprint(title, `, longest `, process_time(longest[0].Time))
+	}
+	if ts, err := state.Cur.getTimestamp(); err == nil {
+		title = fmt.Sprint(ts.Format(`15:04:05`), `  `, title)
+	}
+	lines = append(lines, fmt.Sprint(title, `  (by `, t.sortby, `)`), ``)
+
+	// As many tables side by side as fit
+	table_width := TOP_NAME_WIDTH + 15 + 3 // and the gap between them
+	per_row := int(t.width) / table_width
+	if per_row < 1 {
+		per_row = 1
+	}
+	for i := 0; i < len(t.groupings); i += per_row {
+		var tables [][]string
+		height := 0
+		for _, by := range t.groupings[i:min_int(i+per_row, len(t.groupings))] {
+			table := t.groupTable(procs, by)
+			tables = append(tables, table)
+			if len(table) > height {
+				height = len(table)
+			}
+		}
+		for row := 0; row < height; row++ {
+			var cells []string
+			for _, table := range tables {
+				cell := strings.Repeat(` `, table_width-3)
+				if row < len(table) {
+					cell = table[row]
+				}
+				cells = append(cells, cell)
+			}
+			lines = append(lines, strings.TrimRight(strings.Join(cells, `   `), ` `))
+		}
+		lines = append(lines, ``)
+	}
+
+	lines = append(lines, fmt.Sprintf("%8s %-12s %-15s %-12s %5s %-20s %s", `id`, `user`, `host`, `db`, `time`, `state`, `info`))
+	for i, p := range longest {
+		if i == t.limit {
+			break
+		}
+		info := strings.Join(strings.Fields(p.Info), ` `)
+		lines = append(lines, fmt.Sprintf("%8s %-12.12s %-15.15s %-12.12s %5s %-20.20s %s", p.Id, p.User, groupName(p, `host`), p.Db,
+			process_time(p.Time), p.State, info))
+	}
+
+	// Cut every line to the screen, by characters so names and queries don't end in half a rune
+	var buf FixedWidthBuffer
+	buf.SetWidth(t.width)
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Thread times are whole seconds, so 0 isn't 0.0ns
+func process_time(secs float64) string {
+	if secs < 1 {
+		return `0s`
+	}
+	return collapse_number(secs, 5, 0, SecondUnits)
+}
+
+func min_int(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package myqlib
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var topProcesses = []Process{
+	{Id: `1`, User: `system user`, Command: `Connect`, Time: 8120, State: `Waiting for master`},
+	{Id: `790`, User: `app`, Host: `10.0.0.6:51234`, Db: `shop`, Command: `Sleep`, Time: 12},
+	{Id: `811`, User: `app`, Host: `10.0.0.5:40022`, Db: `shop`, Command: `Query`, Time: 3, State: `Sending data`},
+	{Id: `815`, User: `app`, Host: `10.0.0.6:51240`, Db: `shop`, Command: `Query`, Time: 42, State: `updating`},
+	{Id: `901`, User: `root`, Host: `localhost`, Command: `Query`, State: `starting`},
+	{Id: `902`, User: `report`, Host: `10.0.0.9:3344`, Db: `dw`, Command: `Query`, Time: 600, State: `Sending data`},
+}
+
+func TestGroupProcesses(t *testing.T) {
+	names := func(groups []ProcessGroup) string {
+		var names []string
+		for _, g := range groups {
+			names = append(names, g.Name)
+		}
+		return strings.Join(names, `,`)
+	}
+
+	tests := []struct{ by, sortby, expected string }{
+		{`user`, SORT_COUNT, `app,report,root,system user`},
+		{`user`, SORT_TIME, `report,app,root,system user`},
+		{`user`, SORT_NAME, `app,report,root,system user`},
+		{`host`, SORT_COUNT, `10.0.0.6,-,10.0.0.5,10.0.0.9,localhost`},
+		{`db`, SORT_ACTIVE, `shop,-,dw`},
+		{`state`, SORT_COUNT, `Sending data,-,Waiting for master,starting,updating`},
+	}
+	for _, test := range tests {
+		if got := names(groupProcesses(topProcesses, test.by, test.sortby)); got != test.expected {
+			t.Error(test.by, test.sortby, "expected", test.expected, "got", got)
+		}
+	}
+
+	app := groupProcesses(topProcesses, `user`, SORT_COUNT)[0]
+	if app.Threads != 3 || app.Active != 2 || app.Longest != 42 {
+		t.Errorf("Unexpected group: %+v", app)
+	}
+}
+
+func TestProcessTop(t *testing.T) {
+	top, err := NewProcessTop([]string{`user`, `db`}, SORT_COUNT, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top.SetWidth(120)
+
+	sample := MyqSample{TIMESTAMP_KEY: `1414157680`}
+	for _, p := range topProcesses {
+		addProcess(sample, map[string]string{`Id`: p.Id, `User`: p.User, `Host`: p.Host, `db`: p.Db, `Command`: p.Command,
+			`Time`: strconv.FormatFloat(p.Time, 'f', -1, 64), `State`: p.State, `Info`: "SELECT 1\n  FROM dual"})
+	}
+	var buf bytes.Buffer
+	if err := top.Render(&buf, &MyqState{Cur: sample}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+
+	if !strings.HasSuffix(lines[0], `6 threads, 4 active, longest 600s  (by count)`) {
+		t.Errorf("Unexpected title: %q", lines[0])
+	}
+	if lines[2] != `user                 thds actv  long   db                   thds actv  long` {
+		t.Errorf("Unexpected header: %q", lines[2])
+	}
+	if lines[3] != `app                     3    2   42s   shop                    3    2   42s` || len(lines[5]) != 0 {
+		t.Errorf("Expected 2 rows of each: %q", lines[3:6])
+	}
+	if lines[7] != `     902 report       10.0.0.9        dw            600s Sending data         SELECT 1 FROM dual` {
+		t.Errorf("Unexpected longest query: %q", lines[7])
+	}
+	if len(lines) != 10 {
+		t.Error("Expected 2 queries, got", lines[6:])
+	}
+
+	// Narrow screens get one table per row
+	top.SetWidth(40)
+	buf.Reset()
+	top.Render(&buf, &MyqState{Cur: sample})
+	if lines := strings.Split(buf.String(), "\n"); lines[2] != `user                 thds actv  long` || lines[6] != `db                   thds actv  long` {
+		t.Errorf("Unexpected narrow tables: %q", lines)
+	}
+
+	// Multi-byte queries are cut by characters
+	top.SetWidth(89)
+	buf.Reset()
+	top.Render(&buf, &MyqState{Cur: MyqSample{processKey(`id`, `7`): `7`, processKey(`command`, `7`): `Query`,
+		processKey(`time`, `7`): `5`, processKey(`info`, `7`): `SELECT 'ünïcödé'`}})
+	lines = strings.Split(buf.String(), "\n")
+	if last := lines[len(lines)-2]; !utf8.ValidString(last) || !strings.HasSuffix(last, `SELECT 'ünï`) {
+		t.Errorf("Unexpected cut query: %q", last)
+	}
+
+	if _, err := NewProcessTop([]string{`user`, `nope`}, SORT_COUNT, 10); err == nil {
+		t.Error("Expected an error for a bad grouping")
+	}
+	if _, err := NewProcessTop([]string{`user`}, `nope`, 10); err == nil {
+		t.Error("Expected an error for a bad sort")
+	}
+}
@@ -0,0 +1,94 @@
+package myqlib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Escape the same characters the mysql cli does in batch mode so values can't break up lines
+var batchEscaper = strings.NewReplacer("\\", `\\`, "\t", `\t`, "\n", `\n`)
+
+// Wraps another Loader and writes every sample it loads to files a FileLoader can read back
+type RecordingLoader struct {
+	Loader
+	statusFile    string
+	variablesFile string
+}
+
+func NewRecordingLoader(l Loader, statusFile, varFile string) *RecordingLoader {
+	return &RecordingLoader{l, statusFile, varFile}
+}
+
+// Pass samples from ch through to the returned channel, writing each to filename on the way
+func (l RecordingLoader) record(ch chan MyqSample, filename string, errs chan error) (chan MyqSample, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var out = make(chan MyqSample)
+	go func() {
+		defer file.Close()
+		defer close(out)
+
+		w := bufio.NewWriter(file)
+		failed := false
+		for sample := range ch {
+			// Gaps can't be recorded, restarts will still be picked up from the uptime on replay
+			if sample != nil && !failed {
+				if _, ok := sample[TIMESTAMP_KEY]; !ok {
+					sample.setTimestamp(time.Now())
+				}
+				if err := writeSample(w, sample); err != nil {
+					// Keep the samples flowing, just stop recording
+					sendError(errs, filename, err)
+					failed = true
+				}
+			}
+			out <- sample
+		}
+	}()
+
+	return out, nil
+}
+
+// Write a sample in mysql cli batch format, preceeded by a TS line (like pt-stalk) and followed by END_STRING
+func writeSample(w *bufio.Writer, sample MyqSample) error {
+	ts, _ := sample.getTimestamp()
+	fmt.Fprintf(w, "TS %s %s\n", sample.getStr(TIMESTAMP_KEY), ts.Format(`2006-01-02 15:04:05`))
+
+	var keys []string
+	for k := range sample {
+		if k != TIMESTAMP_KEY { // that's the TS line
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", k, batchEscaper.Replace(sample[k]))
+	}
+	fmt.Fprintln(w, END_STRING)
+
+	// Flush every sample so the file is usable even if we get killed
+	return w.Flush()
+}
+
+func (l RecordingLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	ch, err := l.Loader.getStatus(errs)
+	if err != nil {
+		return nil, err
+	}
+	return l.record(ch, l.statusFile, errs)
+}
+
+func (l RecordingLoader) getVars(errs chan error) (chan MyqSample, error) {
+	ch, err := l.Loader.getVars(errs)
+	if err != nil || l.variablesFile == "" {
+		return ch, err
+	}
+	return l.record(ch, l.variablesFile, errs)
+}
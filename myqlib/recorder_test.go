@@ -0,0 +1,72 @@
+package myqlib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Run all the states from the loader through the view and collect the data lines
+func viewOutput(t *testing.T, l Loader, v View) (lines []string) {
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state := range states {
+		for line := range v.Data(state) {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myq_record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	statusfile := filepath.Join(dir, "status")
+	varfile := filepath.Join(dir, "status.vars")
+
+	// Read some real samples to record
+	var samples []MyqSample
+	fl := NewFileLoader(1*time.Second, "../testdata/mysql.two", "")
+	ch, err := fl.getStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sample := range ch {
+		samples = append(samples, sample)
+	}
+	samples[1]["compression"] = "tab\tand\nnewline"
+
+	v := DefaultViews()[`cttf`]
+	recorded := viewOutput(t, NewRecordingLoader(sliceLoader{loaderInterval(1 * time.Second), samples}, statusfile, varfile), v)
+
+	// The (missing) vars should not have been recorded
+	if _, err := os.Stat(varfile); !os.IsNotExist(err) {
+		t.Error("Variables file was created:", err)
+	}
+
+	replayed := viewOutput(t, NewFileLoader(1*time.Second, statusfile, ""), v)
+	if len(recorded) != 2 || len(recorded) != len(replayed) {
+		t.Fatal("Replay has", len(replayed), "lines, expected", len(recorded))
+	}
+	for i := range recorded {
+		if recorded[i] != replayed[i] {
+			t.Errorf("Line %d differs:\n%s\n%s", i, recorded[i], replayed[i])
+		}
+	}
+
+	// The escaped value stays on its line
+	rl := NewFileLoader(1*time.Second, statusfile, "")
+	ch, _ = rl.getStatus(nil)
+	<-ch
+	second := <-ch
+	if second.getStr(`compression`) != `tab\tand\nnewline` {
+		t.Error("Unexpected compression value:", second.getStr(`compression`))
+	}
+}
@@ -0,0 +1,248 @@
+package myqlib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// The commands for replication status, SHOW REPLICA STATUS is 8.0.22+ (and the only one in 8.4)
+	REPLICA_COMMAND string = "SHOW REPLICA STATUS"
+	SLAVE_COMMAND   string = "SHOW SLAVE STATUS"
+
+	// prefix of replication status keys in a sample: repl_<field> for the default channel and
+	// repl_<field>.<channel> for named ones.  Lowercase, so recorded samples read back the same.
+	REPL_PREFIX = "repl_"
+)
+
+// SHOW REPLICA STATUS for live loaders to run too, or SHOW SLAVE STATUS before 8.0.22
+var ReplicationQuery = ExtraQuery{query: REPLICA_COMMAND, fallback: SLAVE_COMMAND, add: addReplication}
+
+// SHOW REPLICA STATUS field names use replica and source, store them with the older slave and master names
+func replField(field string) string {
+	words := strings.Split(strings.ToLower(field), `_`)
+	for i, word := range words {
+		switch word {
+		case `replica`:
+			words[i] = `slave`
+		case `source`:
+			words[i] = `master`
+		}
+	}
+	return strings.Join(words, `_`)
+}
+
+// The key of a replication field in a sample
+func replKey(field, channel string) string {
+	if channel == "" {
+		return fmt.Sprint(REPL_PREFIX, field)
+	}
+	return fmt.Sprint(REPL_PREFIX, field, `.`, channel)
+}
+
+// Add one row of SHOW SLAVE STATUS (one channel) to a sample
+func addReplication(sample MyqSample, row map[string]string) {
+	fields := map[string]string{}
+	for name, value := range row {
+		fields[replField(name)] = value
+	}
+	channel := fields[`channel_name`]
+	if channel == "" {
+		channel = fields[`connection_name`] // MariaDB multi-source
+	}
+	channel = strings.ToLower(channel)
+
+	for field, value := range fields {
+		sample[replKey(field, channel)] = value
+	}
+}
+
+// The replication channels in a sample, the default ("") first
+func replChannels(sample MyqSample) (channels []string) {
+	running := fmt.Sprint(REPL_PREFIX, `slave_io_running`)
+	for key := range sample {
+		if key == running {
+			channels = append(channels, "")
+		} else if strings.HasPrefix(key, running+`.`) {
+			channels = append(channels, strings.TrimPrefix(key, running+`.`))
+		}
+	}
+	sort.Strings(channels)
+	return
+}
+
+// Replication Columns show a line for every replication channel
+type ReplCol struct {
+	DefaultCol
+	NumCol
+	value func(state *MyqState, channel string) interface{} // float64, string or nil
+}
+
+func NewReplCol(name, help string, width int64, precision int64, units UnitsDef, value func(*MyqState, string) interface{}) ReplCol {
+	return ReplCol{DefaultCol{name, help, width}, NumCol{precision, units}, value}
+}
+
+// The value for the first channel (the default, if there is one)
+func (c ReplCol) Value(state *MyqState) interface{} {
+	if channels := replChannels(state.Cur); len(channels) > 0 {
+		return c.value(state, channels[0])
+	}
+	return nil
+}
+
+func (c ReplCol) Data(state *MyqState) chan string {
+	channels := replChannels(state.Cur)
+	ch := make(chan string, len(channels)+1)
+	defer close(ch)
+
+	// Not a replica
+	if len(channels) == 0 {
+		ch <- column_filler(c)
+		return ch
+	}
+
+	for _, channel := range channels {
+		switch val := c.value(state, channel).(type) {
+		case float64:
+			ch <- fit_string(collapse_number(val, c.Width(), c.precision, c.units), c.Width())
+		case string:
+			ch <- fit_string(val, c.Width())
+		default:
+			ch <- column_filler(c)
+		}
+	}
+	return ch
+}
+
+// A replication field as is
+func repl_string(field string) func(*MyqState, string) interface{} {
+	return func(state *MyqState, channel string) interface{} {
+		if val, err := state.Cur.getString(replKey(field, channel)); err == nil {
+			return val
+		}
+		return nil
+	}
+}
+
+// A numeric replication field, nil if it's missing or NULL (like Seconds_Behind_Master with the SQL thread stopped)
+func repl_gauge(field string) func(*MyqState, string) interface{} {
+	return func(state *MyqState, channel string) interface{} {
+		if val, err := state.Cur.getFloat(replKey(field, channel)); err == nil {
+			return val
+		}
+		return nil
+	}
+}
+
+// The rate of a log position, which only makes sense while it's in the same log file
+func repl_position_rate(pos_field, file_field string) func(*MyqState, string) interface{} {
+	return func(state *MyqState, channel string) interface{} {
+		pos, file := replKey(pos_field, channel), replKey(file_field, channel)
+		cur, cerr := state.Cur.getFloat(pos)
+		prev, perr := state.Prev.getFloat(pos)
+		if cerr != nil || perr != nil || state.Gap || state.Cur.getStr(file) != state.Prev.getStr(file) {
+			return nil
+		}
+		return calculate_rate(cur, prev, state.SecondsDiff)
+	}
+}
+
+// The last IO or SQL thread error with its number, "" if there isn't one
+func repl_error(state *MyqState, channel string) interface{} {
+	for _, thread := range []string{`io`, `sql`} {
+		errno := state.Cur.getStr(replKey(fmt.Sprint(`last_`, thread, `_errno`), channel))
+		if errno != "" && errno != "0" {
+			return fmt.Sprint(errno, `: `, state.Cur.getStr(replKey(fmt.Sprint(`last_`, thread, `_error`), channel)))
+		}
+	}
+	return ""
+}
+
+// The replication view's columns
+func replication_cols() []Col {
+	return []Col{
+		NewReplCol(`chan`, `Replication channel (blank for the default)`, 8, 0, NumberUnits, func(state *MyqState, channel string) interface{} {
+			return channel
+		}),
+		NewGroupCol(`Threads`, `Replication threads`,
+			NewReplCol(`io`, `IO thread running (Yes, No or Connecting)`, 3, 0, NumberUnits, repl_string(`slave_io_running`)),
+			NewReplCol(`sql`, `SQL thread running`, 3, 0, NumberUnits, repl_string(`slave_sql_running`)),
+		),
+		NewReplCol(`lag`, `Seconds behind master`, 5, 0, SecondUnits, repl_gauge(`seconds_behind_master`)),
+		NewGroupCol(`Relay Log`, `Relay log stats`,
+			NewReplCol(`space`, `Relay log space`, 5, 0, MemoryUnits, repl_gauge(`relay_log_space`)),
+			NewReplCol(`read`, `Master binlog read by the IO thread / s`, 5, 0, MemoryUnits, repl_position_rate(`read_master_log_pos`, `master_log_file`)),
+			NewReplCol(`exec`, `Master binlog executed by the SQL thread / s`, 5, 0, MemoryUnits, repl_position_rate(`exec_master_log_pos`, `relay_master_log_file`)),
+		),
+		NewReplCol(`error`, `Last IO or SQL thread error`, 40, 0, NumberUnits, repl_error),
+	}
+}
+
+// Parse the vertical (\G) output of the mysql cli into rows, for SHOW SLAVE STATUS in BATCH output.
+// The free-form Status of SHOW ENGINE INNODB STATUS is collected into monitor instead.
+type verticalRows struct {
+	rows      []map[string]string
+	monitor   []string
+	inMonitor bool
+	open      bool   // still in the last row
+	field     string // the last field of the last row, any other lines continue its value
+	colon     int    // where the last row's names end, the mysql cli pads them so the colons line up
+	blanks    int    // blank lines that may be inside a multi-line value
+}
+
+var (
+	verticalFieldRe = regexp.MustCompile(`^\s*(\w+):( |$)`) // Field: value
+	batchLineRe     = regexp.MustCompile(`^\w+\t`)          // Variable_name<TAB>Value after the vertical rows
+)
+
+// Returns true if the line was part of vertical output
+func (v *verticalRows) parseLine(line string) bool {
+	if strings.Contains(line, `INNODB MONITOR OUTPUT`) {
+		if strings.Contains(line, `END OF INNODB MONITOR OUTPUT`) {
+			v.inMonitor = false
+		} else {
+			v.inMonitor, v.monitor, v.open = true, nil, false
+			if len(v.rows) > 0 {
+				v.rows = v.rows[:len(v.rows)-1] // the row is the monitor's, not a replication channel
+			}
+		}
+	}
+	if v.inMonitor || strings.Contains(line, `END OF INNODB MONITOR OUTPUT`) {
+		v.monitor = append(v.monitor, line)
+		return true
+	}
+
+	if strings.HasPrefix(line, `***`) && strings.Contains(line, `. row *`) {
+		v.rows = append(v.rows, map[string]string{})
+		v.open, v.field, v.blanks = true, "", 0
+		return true
+	}
+	if !v.open {
+		return false
+	}
+	if batchLineRe.MatchString(line) {
+		v.open = false // on to the status
+		return false
+	}
+
+	row := v.rows[len(v.rows)-1]
+	if m := verticalFieldRe.FindStringSubmatchIndex(line); m != nil && (v.field == "" || m[3] == v.colon) {
+		v.field, v.colon, v.blanks = line[m[2]:m[3]], m[3], 0
+		row[v.field] = strings.TrimPrefix(line[m[3]+1:], ` `)
+		return true
+	}
+	if v.field == "" {
+		return false
+	}
+
+	// The next line of a multi-line value, like a GTID set or a query
+	if line == "" {
+		v.blanks++
+	} else {
+		row[v.field] += strings.Repeat("\n", v.blanks+1) + line
+		v.blanks = 0
+	}
+	return true
+}
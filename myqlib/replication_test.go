@@ -0,0 +1,123 @@
+package myqlib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplField(t *testing.T) {
+	tests := map[string]string{
+		`Replica_IO_Running`:    `slave_io_running`,
+		`Seconds_Behind_Source`: `seconds_behind_master`,
+		`Relay_Source_Log_File`: `relay_master_log_file`,
+		`Slave_SQL_Running`:     `slave_sql_running`,
+		`Replicate_Do_DB`:       `replicate_do_db`,
+	}
+	for field, expected := range tests {
+		if got := replField(field); got != expected {
+			t.Error(field, "expected", expected, "got", got)
+		}
+	}
+}
+
+func replStates(t *testing.T) []*MyqState {
+	l := NewFileLoader(1*time.Second, "../testdata/mysql.repl", "")
+	ch, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []*MyqState
+	for state := range ch {
+		states = append(states, state)
+	}
+	if len(states) != 2 {
+		t.Fatal("Expected 2 states, got", len(states))
+	}
+	return states
+}
+
+func TestReplicationSamples(t *testing.T) {
+	sample := replStates(t)[0].Cur
+
+	// Status is still there
+	if sample.getStr(`uptime`) != `100` || sample.getStr(`com_select`) != `1000` {
+		t.Error("Missing status:", sample)
+	}
+	if sample.getStr(`repl_seconds_behind_master`) != `0` || sample.getStr(`repl_master_host`) != `db1` {
+		t.Error("Unexpected default channel:", sample)
+	}
+	if sample.getStr(`repl_last_sql_error.east`) != `Duplicate entry '1' for key 'PRIMARY'` {
+		t.Error("Unexpected east channel error:", sample.getStr(`repl_last_sql_error.east`))
+	}
+	if val, err := sample.getString(`repl_seconds_behind_master.east`); err != nil || val != `` {
+		t.Error("Expected NULL lag for east:", val, err)
+	}
+	if channels := replChannels(sample); !reflect.DeepEqual(channels, []string{``, `east`}) {
+		t.Error("Unexpected channels:", channels)
+	}
+
+	// GTID sets go on for a line per server
+	if gtids := sample.getStr(`repl_executed_gtid_set`); gtids != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,\n4b1a2c33-71ca-11e1-9e33-c80aa9429562:1-77" {
+		t.Errorf("Unexpected executed GTID set: %q", gtids)
+	}
+	if sample.getStr(`repl_retrieved_gtid_set`) != `4b1a2c33-71ca-11e1-9e33-c80aa9429562:60-77` || sample.getStr(`repl_channel_name`) != `` {
+		t.Error("Unexpected fields after the GTID set:", sample)
+	}
+	for key := range sample {
+		if strings.HasPrefix(key, `repl_4b1a2c33`) {
+			t.Error("A GTID set line became a field:", key)
+		}
+	}
+}
+
+// Vertical values can have blank lines, colons and tabs, they end at the next field, row or status line
+func TestVerticalMultiLine(t *testing.T) {
+	var v verticalRows
+	for _, line := range strings.Split("*************************** 1. row ***************************\n"+
+		"Field: first\n\n  second: not a field\n\tthird\n"+
+		"  Two: 2\n\n"+
+		"Uptime\t100", "\n") {
+		v.parseLine(line)
+	}
+	expected := []map[string]string{{`Field`: "first\n\n  second: not a field\n\tthird", `Two`: `2`}}
+	if !reflect.DeepEqual(v.rows, expected) {
+		t.Errorf("Unexpected rows: %q", v.rows)
+	}
+}
+
+func TestReplView(t *testing.T) {
+	states := replStates(t)
+	v := DefaultViews()[`repl`]
+	v.SetTimeCol(&Runtime_col)
+
+	var lines []string
+	for line := range v.Data(states[1]) {
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatal("Expected a line per channel, got", lines)
+	}
+	if !strings.HasPrefix(lines[0], `      1s          Yes Yes    2s 1028K 4096b 4096b `) {
+		t.Errorf("Unexpected default channel line: %q", lines[0])
+	}
+	// The IO thread moved on to a new binlog, so there's no rate for it
+	if !strings.HasPrefix(lines[1], `             east Con  No     - 2048b     -    0b 2003: error reconnecting`) {
+		t.Errorf("Unexpected east channel line: %q", lines[1])
+	}
+
+	// Machine readable values are for the default channel
+	if val := viewValues(v, states[1])[4].value; val != 2.0 {
+		t.Error("Expected the default channel's lag, got", val)
+	}
+
+	// Not a replica
+	var empty []string
+	for line := range v.Data(&MyqState{Cur: MyqSample{`uptime`: `1`}}) {
+		empty = append(empty, line)
+	}
+	if len(empty) != 1 || !strings.Contains(empty[0], `-`) {
+		t.Error("Expected one line of filler, got", empty)
+	}
+}
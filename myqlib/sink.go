@@ -0,0 +1,161 @@
+package myqlib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sinks send the computed column values of a view for every state somewhere else
+type Sink interface {
+	Write(v View, state *MyqState) error
+	Close() error
+}
+
+// Destination that means write to stdout
+const STDOUT_DEST string = "-"
+
+// Open a sink destination: STDOUT_DEST, a tcp://host:port or udp://host:port endpoint, or a file to append to
+func OpenSinkDest(dest string) (io.WriteCloser, error) {
+	switch {
+	case dest == STDOUT_DEST:
+		return nopCloser{os.Stdout}, nil
+	case strings.HasPrefix(dest, "tcp://"):
+		return net.Dial("tcp", strings.TrimPrefix(dest, "tcp://"))
+	case strings.HasPrefix(dest, "udp://"):
+		return net.Dial("udp", strings.TrimPrefix(dest, "udp://"))
+	}
+	return os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// So we don't close stdout
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// When the state was sampled, or now if we don't know
+func stateTime(state *MyqState) time.Time {
+	if ts, err := state.Cur.getTimestamp(); err == nil {
+		return ts
+	}
+	return time.Now()
+}
+
+// Writes InfluxDB line protocol, one line per state with a field for every column
+type InfluxSink struct {
+	w          io.WriteCloser
+	host, view string
+}
+
+func NewInfluxSink(w io.WriteCloser, host, view string) *InfluxSink {
+	return &InfluxSink{w, host, view}
+}
+
+// Measurement names escape commas and spaces, tags and field keys escape '=' too
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	influxKeyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+	influxStringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// The measurement all the views are written to
+const INFLUX_MEASUREMENT string = "myq"
+
+func (s *InfluxSink) Write(v View, state *MyqState) error {
+	var fields bytes.Buffer
+	for _, cv := range viewValues(v, state) {
+		if cv.time {
+			continue // that's the line's timestamp
+		}
+
+		var value string
+		switch val := cv.value.(type) {
+		case float64:
+			if !finite(val) {
+				continue
+			}
+			value = strconv.FormatFloat(val, 'f', -1, 64)
+		case string:
+			value = fmt.Sprint(`"`, influxStringEscaper.Replace(val), `"`)
+		default:
+			continue // missing
+		}
+
+		if fields.Len() > 0 {
+			fields.WriteString(`,`)
+		}
+		key := cv.name
+		if cv.group != "" {
+			key = fmt.Sprint(cv.group, ".", cv.name)
+		}
+		fmt.Fprint(&fields, influxKeyEscaper.Replace(key), `=`, value)
+	}
+
+	// A line needs at least one field
+	if fields.Len() == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(s.w, "%s,host=%s,view=%s %s %d\n", influxMeasurementEscaper.Replace(INFLUX_MEASUREMENT),
+		influxKeyEscaper.Replace(s.host), influxKeyEscaper.Replace(s.view), fields.String(), stateTime(state).UnixNano())
+	return err
+}
+
+func (s *InfluxSink) Close() error {
+	return s.w.Close()
+}
+
+// Writes the Carbon plaintext protocol, one 'myq.<host>.<view>.<group>.<col> value timestamp' line per numeric column
+type GraphiteSink struct {
+	w          io.WriteCloser
+	host, view string
+}
+
+func NewGraphiteSink(w io.WriteCloser, host, view string) *GraphiteSink {
+	return &GraphiteSink{w, host, view}
+}
+
+// The first part of every Graphite metric path
+const GRAPHITE_PREFIX string = "myq"
+
+var (
+	graphiteReadable = strings.NewReplacer(`%`, `pct`, `/`, `_per_`)
+	graphiteInvalid  = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+)
+
+// Make a string safe to use as one node of a metric path
+func graphiteNode(s string) string {
+	return graphiteInvalid.ReplaceAllString(graphiteReadable.Replace(s), `_`)
+}
+
+func (s *GraphiteSink) Write(v View, state *MyqState) error {
+	var buf bytes.Buffer
+	ts := stateTime(state).Unix()
+	base := fmt.Sprint(GRAPHITE_PREFIX, ".", graphiteNode(s.host), ".", graphiteNode(s.view), ".")
+	for _, cv := range viewValues(v, state) {
+		val, ok := cv.value.(float64)
+		if cv.time || !ok || !finite(val) {
+			continue
+		}
+
+		buf.WriteString(base)
+		if cv.group != "" {
+			buf.WriteString(graphiteNode(cv.group))
+			buf.WriteString(".")
+		}
+		fmt.Fprintf(&buf, "%s %s %d\n", graphiteNode(cv.name), strconv.FormatFloat(val, 'f', -1, 64), ts)
+	}
+	_, err := buf.WriteTo(s.w)
+	return err
+}
+
+func (s *GraphiteSink) Close() error {
+	return s.w.Close()
+}
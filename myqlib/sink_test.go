@@ -0,0 +1,161 @@
+package myqlib
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Write the states from mysqladmin.ts through the sink
+func writeSink(t *testing.T, s Sink, view string) {
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.ts", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := DefaultViews()[view]
+	v.SetTimeCol(&Capturetime_col)
+	for state := range states {
+		if err := s.Write(v, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGraphiteSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	lines := make(chan []string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(lines)
+			return
+		}
+		defer conn.Close()
+		var got []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		lines <- got
+	}()
+
+	w, err := OpenSinkDest("tcp://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSink(t, NewGraphiteSink(w, "db1.example.com", "innodb"), "innodb")
+
+	got := <-lines
+	if len(got) != 19 { // 14 numeric columns, only the 5 gauges for the first sample
+		t.Fatal("Expected 19 lines, got", len(got), got)
+	}
+	for _, expected := range []string{
+		"myq.db1_example_com.innodb.Row_Ops.read 275729 1414170881",
+		"myq.db1_example_com.innodb.Buffer_Pool.dirt 0.5422940211470105 1414170881",
+		"myq.db1_example_com.innodb.Log.pct 30.59878995910011 1414170881",
+		"myq.db1_example_com.innodb.Hist 1181 1414170880",
+	} {
+		found := false
+		for _, line := range got {
+			found = found || line == expected
+		}
+		if !found {
+			t.Error("Missing line:", expected)
+		}
+	}
+}
+
+func TestInfluxSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := OpenSinkDest("udp://" + conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSink(t, NewInfluxSink(w, "db1", "qcache"), "qcache")
+
+	var got []string
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(got) < 2 {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(buf[:n]))
+	}
+
+	// Missing values (no variables, no qcache blocks) are left out, the time col is the timestamp
+	expected := "myq,host=db1,view=qcache sel=1398,hits=0,ins=0,notc=0,tot=0,lowm=0 1414170881007000000\n"
+	if got[1] != expected {
+		t.Errorf("Unexpected line:\n%s%s", got[1], expected)
+	}
+	if !strings.HasPrefix(got[0], "myq,host=db1,view=qcache ") {
+		t.Error("Unexpected line:", got[0])
+	}
+}
+
+func TestInfluxSinkEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewInfluxSink(nopCloser{&buf}, "db 1", "test")
+	v := NewNormalView(`test`,
+		NewGroupCol(`Buffer Pool`, `Buffer Pool Stats`,
+			NewStringCol(`state`, `A string`, 4, `state`),
+		),
+	)
+	if err := s.Write(v, &MyqState{Cur: MyqSample{`state`: `say "hi"`, TIMESTAMP_KEY: `1.000`}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "myq,host=db\\ 1,view=test Buffer\\ Pool.state=\"say \\\"hi\\\"\" 1000000000\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected line:\n%s%s", buf.String(), expected)
+	}
+}
+
+func TestSinksFirstState(t *testing.T) {
+	samples := []MyqSample{
+		{`uptime`: `100`, `connections`: `5000`, `threads_running`: `3`, TIMESTAMP_KEY: `100`},
+		{`uptime`: `101`, `connections`: `5007`, `threads_running`: `4`, TIMESTAMP_KEY: `101`},
+	}
+	v := NewNormalView(`test`, NewRateCol(`cons`, `Connections per second`, 4, `connections`, 0, NumberUnits),
+		NewDiffCol(`new`, `New connections`, 4, `connections`, 0, NumberUnits),
+		NewGaugeCol(`run`, `Threads running`, 4, `threads_running`, 0, NumberUnits))
+
+	var influx, graphite bytes.Buffer
+	sinks := []Sink{NewInfluxSink(nopCloser{&influx}, "db1", "test"), NewGraphiteSink(nopCloser{&graphite}, "db1", "test")}
+	states, _, err := GetState(sliceLoader{loaderInterval(1 * time.Second), samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for state := range states {
+		for _, s := range sinks {
+			if err := s.Write(v, state); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// The counter totals in the first sample aren't rates or differences
+	if expected := "myq,host=db1,view=test run=3 100000000000\nmyq,host=db1,view=test cons=7,new=7,run=4 101000000000\n"; influx.String() != expected {
+		t.Errorf("Unexpected lines:\n%s%s", influx.String(), expected)
+	}
+	if expected := "myq.db1.test.run 3 100\nmyq.db1.test.cons 7 101\nmyq.db1.test.new 7 101\nmyq.db1.test.run 4 101\n"; graphite.String() != expected {
+		t.Errorf("Unexpected lines:\n%s%s", graphite.String(), expected)
+	}
+}
@@ -0,0 +1,146 @@
+package myqlib
+
+import (
+	"database/sql"
+	_ "github.com/go-sql-driver/mysql"
+	"strings"
+	"time"
+)
+
+const SQLDRIVER string = "mysql"
+
+// SHOW output via a native MySQL protocol connection on a live server (no mysql cli required)
+type SqlLoader struct {
+	loaderInterval
+	loaderReconnect
+	loaderExtraQueries
+	dsn string // go-sql-driver DSN (like user:pass@tcp(host:3306)/)
+}
+
+func NewSqlLoader(i time.Duration, dsn string) *SqlLoader {
+	return &SqlLoader{loaderInterval: loaderInterval(i), dsn: dsn}
+}
+
+// Run the given command against the server every interval and send back the result in a sample
+func (l SqlLoader) harvestQuery(command string, errs chan error) (chan MyqSample, error) {
+	db, err := sql.Open(SQLDRIVER, l.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// sql.Open doesn't connect, so make sure the server is reachable before we go into the background
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// The sql package reconnects on its own, so a session is just querying until something fails
+	var ch = make(chan MyqSample)
+	go func() {
+		defer db.Close()
+		defer close(ch)
+
+		// The query that works for each extra query, once we know if it needs its fallback
+		queries := make([]string, len(l.loaderExtraQueries))
+		for i, q := range l.loaderExtraQueries {
+			queries[i] = q.query
+		}
+		l.keepAlive(ch, errs, command, func(sessionch chan MyqSample) error {
+			ticker := time.NewTicker(l.getInterval())
+			defer ticker.Stop()
+
+			for {
+				sample, err := querySample(db, command)
+				if err != nil {
+					return err
+				}
+
+				// The extra queries go in the same sample
+				for i, q := range l.loaderExtraQueries {
+					if command != STATUS_COMMAND {
+						break
+					}
+					rows, err := queryRows(db, queries[i])
+					if err != nil && q.fallback != "" && queries[i] == q.query {
+						queries[i] = q.fallback
+						rows, err = queryRows(db, queries[i])
+					}
+					if err != nil {
+						return err
+					}
+					for _, row := range rows {
+						q.add(sample, row)
+					}
+				}
+				sessionch <- sample
+
+				<-ticker.C
+			}
+		})
+	}()
+
+	return ch, nil
+}
+
+// Run a SHOW command returning Variable_name/Value rows and collect them into a MyqSample
+func querySample(db *sql.DB, command string) (MyqSample, error) {
+	rows, err := db.Query(command)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sample := make(MyqSample)
+	for rows.Next() {
+		var key, value sql.RawBytes
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		// NULL values come back as an empty string, same as the mysql cli batch output
+		sample[strings.ToLower(string(key))] = string(value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sample, nil
+}
+
+// Run a command and return every row as a map of column names to values
+func queryRows(db *sql.DB, command string) ([]map[string]string, error) {
+	rows, err := db.Query(command)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := map[string]string{}
+		for i, column := range columns {
+			row[column] = string(values[i])
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func (l SqlLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	return l.harvestQuery(STATUS_COMMAND, errs)
+}
+
+func (l SqlLoader) getVars(errs chan error) (chan MyqSample, error) {
+	return l.harvestQuery(VARIABLES_COMMAND, errs)
+}
@@ -0,0 +1,371 @@
+package myqlib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A minimal stand-in for a MySQL server: accepts any login and answers queries with
+// Variable_name/Value resultsets taken from the samples registered for each query
+type standinServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	results  map[string][]MyqSample  // query -> samples to send back, in order
+	tables   map[string]standinTable // query -> any other resultset, always the same
+}
+
+// A resultset with any columns
+type standinTable struct {
+	columns []string
+	rows    [][]string
+}
+
+func newStandinServer(t *testing.T) *standinServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Can't listen:", err)
+	}
+	s := &standinServer{listener: listener, results: map[string][]MyqSample{}, tables: map[string]standinTable{}}
+	go s.serve()
+	return s
+}
+
+// Every query result comes from the samples in this file, the last one repeats
+func (s *standinServer) addFile(t *testing.T, query, filename string) {
+	l := NewFileLoader(1*time.Second, filename, "")
+	samples, err := l.getStatus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for sample := range samples {
+		s.results[query] = append(s.results[query], sample)
+	}
+}
+
+func (s *standinServer) addTable(query string, table standinTable) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tables[query] = table
+}
+
+func (s *standinServer) dsn() string {
+	return "root@tcp(" + s.listener.Addr().String() + ")/"
+}
+
+func (s *standinServer) close() {
+	s.listener.Close()
+}
+
+func (s *standinServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *standinServer) nextResult(query string) (MyqSample, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	samples, ok := s.results[query]
+	if !ok || len(samples) == 0 {
+		return nil, false
+	}
+	if len(samples) > 1 {
+		s.results[query] = samples[1:]
+	}
+	return samples[0], true
+}
+
+func (s *standinServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	// Handshake v10, mysql_native_password with a fixed scramble
+	var hs bytes.Buffer
+	hs.WriteByte(10)
+	hs.WriteString("5.6.99-standin\x00")
+	hs.Write([]byte{1, 0, 0, 0})
+	hs.WriteString("abcdefgh\x00")
+	hs.Write([]byte{0x01, 0xa2}) // long password, protocol 41, transactions, secure connection
+	hs.WriteByte(33)
+	hs.Write([]byte{0x02, 0x00})
+	hs.Write([]byte{0x08, 0x00}) // plugin auth
+	hs.WriteByte(21)
+	hs.Write(make([]byte, 10))
+	hs.WriteString("ijklmnopqrst\x00")
+	hs.WriteString("mysql_native_password\x00")
+	writePacket(conn, 0, hs.Bytes())
+
+	// Whatever the client sends, it gets in
+	if _, _, err := readPacket(conn); err != nil {
+		return
+	}
+	writePacket(conn, 2, okPacket())
+
+	for {
+		_, data, err := readPacket(conn)
+		if err != nil || len(data) == 0 {
+			return
+		}
+
+		switch data[0] {
+		case 0x01: // COM_QUIT
+			return
+		case 0x03: // COM_QUERY
+			s.mutex.Lock()
+			table, ok := s.tables[string(data[1:])]
+			s.mutex.Unlock()
+			if ok {
+				writeTable(conn, table)
+				continue
+			}
+			sample, ok := s.nextResult(string(data[1:]))
+			if !ok {
+				writePacket(conn, 1, errPacket("Unknown query: "+string(data[1:])))
+				continue
+			}
+			writeResultset(conn, sample)
+		default: // COM_PING and friends
+			writePacket(conn, 1, okPacket())
+		}
+	}
+}
+
+func readPacket(r io.Reader) (seq byte, data []byte, err error) {
+	header := make([]byte, 4)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	data = make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	return header[3], data, err
+}
+
+func writePacket(w io.Writer, seq byte, data []byte) {
+	header := []byte{byte(len(data)), byte(len(data) >> 8), byte(len(data) >> 16), seq}
+	w.Write(append(header, data...))
+}
+
+func okPacket() []byte {
+	return []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}
+}
+
+func eofPacket() []byte {
+	return []byte{0xfe, 0x00, 0x00, 0x02, 0x00}
+}
+
+func errPacket(msg string) []byte {
+	var b bytes.Buffer
+	b.WriteByte(0xff)
+	binary.Write(&b, binary.LittleEndian, uint16(1064))
+	b.WriteString("#42000")
+	b.WriteString(msg)
+	return b.Bytes()
+}
+
+func lenencString(b *bytes.Buffer, s string) {
+	// Our strings are short enough for the 1 and 3 byte forms
+	if len(s) < 251 {
+		b.WriteByte(byte(len(s)))
+	} else {
+		b.Write([]byte{0xfc, byte(len(s)), byte(len(s) >> 8)})
+	}
+	b.WriteString(s)
+}
+
+func writeResultset(w io.Writer, sample MyqSample) {
+	table := standinTable{columns: []string{"Variable_name", "Value"}}
+	for key, value := range sample {
+		table.rows = append(table.rows, []string{key, value})
+	}
+	writeTable(w, table)
+}
+
+func writeTable(w io.Writer, table standinTable) {
+	seq := byte(1)
+	next := func(data []byte) {
+		writePacket(w, seq, data)
+		seq++
+	}
+
+	next([]byte{byte(len(table.columns))}) // column count
+	for _, name := range table.columns {
+		var col bytes.Buffer
+		for _, s := range []string{"def", "", "", "", name, name} {
+			lenencString(&col, s)
+		}
+		col.WriteByte(0x0c)
+		col.Write([]byte{33, 0})         // charset
+		col.Write([]byte{0, 4, 0, 0})    // column length
+		col.WriteByte(0xfd)              // VAR_STRING
+		col.Write([]byte{0, 0, 0, 0, 0}) // flags, decimals, filler
+		next(col.Bytes())
+	}
+	next(eofPacket())
+
+	for _, values := range table.rows {
+		var row bytes.Buffer
+		for _, value := range values {
+			lenencString(&row, value)
+		}
+		next(row.Bytes())
+	}
+	next(eofPacket())
+}
+
+func TestSqlLoader(t *testing.T) {
+	s := newStandinServer(t)
+	defer s.close()
+	s.addFile(t, STATUS_COMMAND, "../testdata/mysql.two")
+	s.addFile(t, VARIABLES_COMMAND, "../testdata/variables")
+
+	l := NewSqlLoader(1*time.Second, s.dsn())
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-states
+	if first.Prev != nil {
+		t.Error("First state has a previous sample")
+	}
+	if first.Cur.getStr(`compression`) != `OFF` {
+		t.Error("Unexpected compression value:", first.Cur.getStr(`compression`))
+	}
+	if first.Cur.getStr(`V_binlog_format`) != `ROW` {
+		t.Error("Variables missing from state, V_binlog_format:", first.Cur.getStr(`V_binlog_format`))
+	}
+
+	second := <-states
+	if second.Prev == nil {
+		t.Fatal("Second state has no previous sample")
+	}
+	if second.SecondsDiff != 1 {
+		t.Error("Unexpected SecondsDiff:", second.SecondsDiff)
+	}
+}
+
+func TestSqlLoaderExtraQueries(t *testing.T) {
+	// A server older than 8.0.22, without SHOW REPLICA STATUS
+	s := newStandinServer(t)
+	defer s.close()
+	s.addFile(t, STATUS_COMMAND, "../testdata/mysql.two")
+	s.addFile(t, VARIABLES_COMMAND, "../testdata/variables")
+
+	processlist := ProcesslistSources[`performance_schema`]
+	tests := []struct {
+		name  string
+		extra ExtraQuery
+		query string // the one the server answers
+		table standinTable
+		check func(sample MyqSample) bool
+	}{
+		{`replication`, ReplicationQuery, SLAVE_COMMAND, standinTable{
+			[]string{"Slave_IO_Running", "Slave_SQL_Running", "Seconds_Behind_Master", "Channel_Name"},
+			[][]string{{"Yes", "Yes", "3", ""}, {"Yes", "No", "", "West"}},
+		}, func(sample MyqSample) bool {
+			return sample.getStr(`repl_seconds_behind_master`) == `3` && sample.getStr(`repl_slave_sql_running.west`) == `No`
+		}},
+		{`innodb status`, InnodbStatusQuery, INNODB_STATUS_COMMAND, standinTable{
+			[]string{"Type", "Name", "Status"},
+			[][]string{{"InnoDB", "", "\n------------\nTRANSACTIONS\n------------\nHistory list length 42\n---TRANSACTION 5A0F0E, ACTIVE 7 sec\n"}},
+		}, func(sample MyqSample) bool {
+			return sample.getStr(`innodb_status_history_list_length`) == `42` && sample.getStr(`innodb_status_longest_transaction`) == `7`
+		}},
+		{`processlist`, ProcesslistQuery(processlist), processlist, standinTable{
+			[]string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"},
+			[][]string{{"12", "app", "10.0.0.5", "shop", "Query", "3", "executing", "SELECT 1"}, {"13", "app", "10.0.0.5", "", "Sleep", "9", "", ""}},
+		}, func(sample MyqSample) bool {
+			procs := Processes(sample)
+			return len(procs) == 2 && procs[0].Info == `SELECT 1` && procs[1].Command == `Sleep`
+		}},
+		{`digests`, DigestsQuery, DIGEST_QUERY, standinTable{
+			[]string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "COUNT_STAR", "SUM_TIMER_WAIT", "SUM_ROWS_EXAMINED", "SUM_ROWS_SENT"},
+			[][]string{{"shop", "6e1e9b0c", "SELECT * FROM orders WHERE id = ?", "1000", "2000000000000", "1000", "1000"}},
+		}, func(sample MyqSample) bool {
+			return sample.getStr(digestKey(`count_star`, `shop.6e1e9b0c`)) == `1000` && sample.getStr(digestKey(`digest_text`, `shop.6e1e9b0c`)) != ``
+		}},
+		{`waits`, WaitsQuery, WAITS_QUERY, standinTable{
+			[]string{"EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT"},
+			[][]string{{"wait/io/file/innodb/innodb_log_file", "5000", "10000000000000"}, {"wait/lock/table/sql/handler", "10", "20000000"}},
+		}, func(sample MyqSample) bool {
+			return sample.getStr(waitKey(`count_star`, `wait/io/file/innodb/innodb_log_file`)) == `5000` && sample.getStr(waitKey(`sum_timer_wait`, `wait/lock/table/sql/handler`)) == `20000000`
+		}},
+	}
+	for _, test := range tests {
+		s.addTable(test.query, test.table)
+	}
+
+	// The first state with the given extra queries
+	first := func(extra ...ExtraQuery) MyqSample {
+		l := NewSqlLoader(1*time.Second, s.dsn())
+		l.SetExtraQueries(extra...)
+		states, _, err := GetState(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state := <-states
+		if state.Cur.getStr(`compression`) != `OFF` {
+			t.Error("Missing status:", state.Cur.getStr(`compression`))
+		}
+		return state.Cur
+	}
+
+	var all []ExtraQuery
+	for _, test := range tests {
+		if sample := first(test.extra); !test.check(sample) {
+			t.Error("Missing", test.name, "in", sample)
+		}
+		all = append(all, test.extra)
+	}
+
+	// All of them go in the same sample
+	sample := first(all...)
+	for _, test := range tests {
+		if !test.check(sample) {
+			t.Error("Missing", test.name, "with every extra query")
+		}
+	}
+}
+
+func TestSqlLoaderNoServer(t *testing.T) {
+	s := newStandinServer(t)
+	dsn := s.dsn()
+	s.close()
+
+	l := NewSqlLoader(1*time.Second, dsn)
+	if _, _, err := GetState(l); err == nil {
+		t.Error("Connected to a closed server")
+	}
+}
+
+func TestSqlLoaderQueryError(t *testing.T) {
+	// No results registered, so every query fails
+	s := newStandinServer(t)
+	defer s.close()
+
+	l := NewSqlLoader(1*time.Second, s.dsn())
+	states, errs, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := <-states; ok {
+		t.Error("How did we get a state?")
+	}
+	if err := <-errs; err == nil {
+		t.Error("No error from a failed query")
+	}
+}
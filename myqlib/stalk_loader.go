@@ -0,0 +1,225 @@
+package myqlib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pt-stalk names every file it collects with the trigger time as a prefix, like 2014_10_24_13_34_40-mysqladmin
+const (
+	STALK_PREFIX_LAYOUT string = "2006_01_02_15_04_05"
+	STALK_STATUS_SUFFIX string = "-mysqladmin"
+	STALK_VARS_SUFFIX   string = "-variables"
+	STALK_INNODB_SUFFIX string = "-innodbstatus" // one at the start and one at the end, -innodbstatus1 and 2
+
+	// How far into each file to look for a TS line, pt-stalk writes them first
+	STALK_TS_LINES = 10
+)
+
+// Load the samples pt-stalk collected for one trigger from its output directory
+type StalkLoader struct {
+	FileLoader
+	trigger     time.Time      // when pt-stalk started collecting, zero if we can't tell
+	location    *time.Location // of the host pt-stalk ran on, for the times in its files
+	innodbFiles []string       // SHOW ENGINE INNODB STATUS files, in the order they were collected
+	start, end  time.Time      // the time range of the window, applied once the samples are stamped
+}
+
+// Find the files for the given trigger prefix in dir, or for the latest trigger if prefix is empty
+func NewStalkLoader(i time.Duration, dir, prefix string) (*StalkLoader, error) {
+	if prefix == "" {
+		found, err := filepath.Glob(filepath.Join(dir, "*"+STALK_STATUS_SUFFIX+"*"))
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, errors.New(fmt.Sprint("No pt-stalk mysqladmin files in ", dir))
+		}
+		sort.Strings(found) // the prefixes sort by time
+		latest := filepath.Base(found[len(found)-1])
+		prefix = latest[:strings.Index(latest, STALK_STATUS_SUFFIX)]
+	}
+
+	statusFile, err := findStalkFile(dir, prefix, STALK_STATUS_SUFFIX)
+	if err != nil {
+		return nil, err
+	}
+	if statusFile == "" {
+		return nil, errors.New(fmt.Sprint("No pt-stalk mysqladmin file for ", prefix, " in ", dir))
+	}
+	// The variables are optional, like with -varfile
+	varFile, err := findStalkFile(dir, prefix, STALK_VARS_SUFFIX)
+	if err != nil {
+		return nil, err
+	}
+
+	innodbFiles, err := filepath.Glob(filepath.Join(dir, prefix+STALK_INNODB_SUFFIX+"*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(innodbFiles)
+
+	// The TS lines are epoch seconds, the prefix is in the time zone of the host pt-stalk ran on
+	// (so only right if that's ours too), we go by it only if there are none
+	trigger, ok := stalkStartTime(dir, prefix)
+	location := time.Local
+	if local, err := time.ParseInLocation(STALK_PREFIX_LAYOUT, prefix, time.UTC); err == nil && ok {
+		// How far the prefix is from the TS lines is the host's offset from UTC
+		location = time.FixedZone("", int(local.Sub(trigger).Round(15*time.Minute).Seconds()))
+	} else if !ok {
+		trigger, _ = time.ParseInLocation(STALK_PREFIX_LAYOUT, prefix, time.Local)
+	}
+	return &StalkLoader{FileLoader: *NewFileLoader(i, statusFile, varFile), trigger: trigger, location: location, innodbFiles: innodbFiles}, nil
+}
+
+// Only load the status samples in the given window.  The mysqladmin file has no TS lines, so the
+// time range is checked here after the samples are stamped, and the variables are all kept.
+func (l *StalkLoader) SetWindow(w SampleWindow) {
+	l.FileLoader.SetWindow(SampleWindow{Skip: w.Skip, Until: w.Until})
+	l.start, l.end = w.Start, w.End
+}
+
+// The (possibly compressed) file for the prefix with the given suffix, or "" if there isn't one
+func findStalkFile(dir, prefix, suffix string) (string, error) {
+	found, err := filepath.Glob(filepath.Join(dir, prefix+suffix+"*"))
+	if err != nil || len(found) == 0 {
+		return "", err
+	}
+	sort.Strings(found) // prefer the uncompressed name
+	return found[0], nil
+}
+
+// pt-stalk doesn't write TS lines into the mysqladmin file, so samples without one are stamped
+// with the time collection started plus how far they are (by uptime) from the start of the file.
+// Each sample also gets the latest SHOW ENGINE INNODB STATUS collected by then (or the first).
+func (l StalkLoader) getStatus(errs chan error) (chan MyqSample, error) {
+	ch, err := l.FileLoader.getStatus(errs)
+	if err != nil {
+		return ch, err
+	}
+	statuses, err := l.innodbStatuses()
+	if err != nil {
+		return nil, err
+	}
+	prev_uptime, stamp := firstUptime(l.statusFile)
+	stamp = stamp && !l.trigger.IsZero()
+	windowed := !l.start.IsZero() || !l.end.IsZero()
+	if !stamp && len(statuses) == 0 && !windowed {
+		return ch, nil
+	}
+
+	var out = make(chan MyqSample)
+	go func() {
+		defer close(out)
+		var offset float64
+		for sample := range ch {
+			if _, ok := sample[TIMESTAMP_KEY]; stamp && sample != nil && !ok {
+				uptime := sample.getF(`uptime`)
+				if uptime >= prev_uptime {
+					offset += uptime - prev_uptime
+				} else {
+					offset += uptime // restarted
+				}
+				prev_uptime = uptime
+				sample.setTimestamp(l.trigger.Add(time.Duration(offset * float64(time.Second))))
+			}
+			if sample != nil && windowed {
+				ts, err := sample.getTimestamp()
+				if err != nil || (!l.start.IsZero() && ts.Before(l.start)) {
+					continue // can't be in the range without a timestamp
+				}
+				if !l.end.IsZero() && ts.Truncate(time.Second).After(l.end) { // to the second, like they're shown
+					for range ch {
+						// let the file loader finish
+					}
+					return
+				}
+			}
+			if sample != nil && len(statuses) > 0 {
+				sample.copyInnodbStatus(latestInnodbStatus(statuses, sample))
+			}
+			out <- sample
+		}
+	}()
+	return out, nil
+}
+
+// The earliest TS line pt-stalk wrote for the trigger (at the top of -variables, -processlist and others)
+func stalkStartTime(dir, prefix string) (start time.Time, found bool) {
+	files, err := filepath.Glob(filepath.Join(dir, prefix+"-*"))
+	if err != nil {
+		return
+	}
+	for _, filename := range files {
+		file, err := openCapture(filename)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for i := 0; i < STALK_TS_LINES && scanner.Scan(); i++ {
+			if ts := parseTimestampLine(scanner.Bytes()); ts != "" {
+				if t, err := (MyqSample{TIMESTAMP_KEY: ts}).getTimestamp(); err == nil && (!found || t.Before(start)) {
+					start, found = t, true
+				}
+				break
+			}
+		}
+		file.Close()
+	}
+	return
+}
+
+// A SHOW ENGINE INNODB STATUS file parsed into sample keys
+type stalkInnodbStatus struct {
+	taken  time.Time
+	sample MyqSample
+}
+
+func (l StalkLoader) innodbStatuses() (statuses []stalkInnodbStatus, err error) {
+	for _, filename := range l.innodbFiles {
+		sample, taken, err := loadInnodbStatus(filename, l.location)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, stalkInnodbStatus{taken, sample})
+	}
+	return statuses, nil
+}
+
+// The last status taken at or before the sample, or the first if they're all after it (or it has no time)
+func latestInnodbStatus(statuses []stalkInnodbStatus, sample MyqSample) MyqSample {
+	latest := statuses[0].sample
+	ts, err := sample.getTimestamp()
+	if err != nil {
+		return latest
+	}
+	for _, status := range statuses {
+		if !status.taken.IsZero() && !status.taken.After(ts) {
+			latest = status.sample
+		}
+	}
+	return latest
+}
+
+// The uptime of the first sample in a capture file, even if a SampleWindow would skip it
+func firstUptime(filename string) (float64, bool) {
+	file, err := openCapture(filename)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if uptime, ok := recordUptime(line); ok {
+			return uptime, true
+		}
+	}
+	return 0, false
+}
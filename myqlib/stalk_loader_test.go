@@ -0,0 +1,109 @@
+package myqlib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStalkLoader(t *testing.T) {
+	l, err := NewStalkLoader(1*time.Second, "../testdata/pt-stalk", "2014_10_24_13_34_40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.variablesFile != "../testdata/pt-stalk/2014_10_24_13_34_40-variables" {
+		t.Error("Unexpected variables file:", l.variablesFile)
+	}
+	// The prefix is the same wall time as the TS lines, so pt-stalk ran in UTC
+	if _, offset := time.Unix(1414157680, 0).In(l.location).Zone(); offset != 0 {
+		t.Error("Unexpected time zone offset:", offset)
+	}
+
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// From the processlist's TS line, wherever we are
+	trigger := time.Unix(1414157680, 5*int64(time.Millisecond))
+	i := 0
+	for state := range states {
+		ts, err := state.Cur.getTimestamp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ts.Equal(trigger.Add(time.Duration(i) * time.Second)) {
+			t.Error("Sample", i, "has timestamp", ts)
+		}
+		if state.Cur.getStr(`innodb_buffer_pool_pages_total`) == "" {
+			t.Error("Sample", i, "is missing status")
+		}
+		i++
+	}
+	if i != 2 {
+		t.Error("Expected 2 states, got", i)
+	}
+}
+
+func TestStalkLoaderWindow(t *testing.T) {
+	trigger := time.Unix(1414157680, 5*int64(time.Millisecond))
+	for _, test := range []struct {
+		window   SampleWindow
+		expected []time.Time
+	}{
+		{SampleWindow{Start: trigger.Add(time.Second).Truncate(time.Second)}, []time.Time{trigger.Add(time.Second)}},
+		{SampleWindow{End: trigger.Truncate(time.Second)}, []time.Time{trigger}},
+		{SampleWindow{Start: trigger.Add(time.Hour)}, nil},
+	} {
+		l, err := NewStalkLoader(1*time.Second, "../testdata/pt-stalk", "2014_10_24_13_34_40")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetWindow(test.window)
+		states, _, err := GetState(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []time.Time
+		for state := range states {
+			ts, err := state.Cur.getTimestamp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, ts)
+			if state.Cur.getStr(VAR_PREFIX+`max_connections`) == "" {
+				t.Error("Sample at", ts, "is missing the variables")
+			}
+		}
+		if len(got) != len(test.expected) {
+			t.Error(test.window, "expected samples at", test.expected, "got", got)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(test.expected[i]) {
+				t.Error(test.window, "expected samples at", test.expected, "got", got)
+			}
+		}
+	}
+}
+
+func TestStalkLoaderLatest(t *testing.T) {
+	l, err := NewStalkLoader(1*time.Second, "../testdata/pt-stalk", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.statusFile != "../testdata/pt-stalk/2014_10_24_14_02_11-mysqladmin" || l.variablesFile != "" {
+		t.Error("Unexpected files:", l.statusFile, l.variablesFile)
+	}
+
+	// No TS lines without the variables or processlist, so it's the prefix in our time zone
+	if trigger, _ := time.ParseInLocation(STALK_PREFIX_LAYOUT, "2014_10_24_14_02_11", time.Local); !l.trigger.Equal(trigger) {
+		t.Error("Unexpected trigger time:", l.trigger)
+	}
+
+	if _, err := NewStalkLoader(1*time.Second, "../testdata/pt-stalk", "2014_01_01_00_00_00"); err == nil {
+		t.Error("Expected an error for a missing trigger")
+	}
+	if _, err := NewStalkLoader(1*time.Second, "../testdata", ""); err == nil {
+		t.Error("Expected an error for a directory without pt-stalk files")
+	}
+}
@@ -0,0 +1,114 @@
+package myqlib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// performance_schema timers are in picoseconds
+const PICOSECONDS float64 = 1e12
+
+// performance_schema summary tables (digests, wait events) have a row per id with cumulative
+// COUNT_STAR and SUM_TIMER_WAIT columns, kept in a sample as <prefix><column>.<id>
+func summaryKey(prefix, field, id string) string {
+	return fmt.Sprint(prefix, field, `.`, id)
+}
+
+// Add the (lowercased) fields of one summary row to a sample
+func addSummary(sample MyqSample, prefix, id string, row map[string]string) {
+	for name, value := range row {
+		sample[summaryKey(prefix, strings.ToLower(name), id)] = value
+	}
+}
+
+// The ids of the summary rows in a sample
+func summaryIds(sample MyqSample, prefix string) (ids []string) {
+	count := summaryKey(prefix, `count_star`, ``)
+	for key := range sample {
+		if strings.HasPrefix(key, count) {
+			ids = append(ids, strings.TrimPrefix(key, count))
+		}
+	}
+	return
+}
+
+// Whether a row's counters went back since the last sample, like after a TRUNCATE TABLE of the summary
+func summary_reset(state *MyqState, prefix, id string) bool {
+	for _, field := range []string{`count_star`, `sum_timer_wait`} {
+		key := summaryKey(prefix, field, id)
+		if prev, err := state.Prev.getFloat(key); err == nil && state.Cur.getF(key) < prev {
+			return true
+		}
+	}
+	return false
+}
+
+// The difference of a summary field since the last sample, nil if the row was reset
+func summary_diff(prefix, field string) func(*MyqState, string) interface{} {
+	return func(state *MyqState, id string) interface{} {
+		if summary_reset(state, prefix, id) {
+			return nil
+		}
+		key := summaryKey(prefix, field, id)
+		return calculate_diff(state.Cur.getF(key), state.Prev.getF(key))
+	}
+}
+
+// The difference of a summary timer since the last sample, in seconds
+func summary_seconds(prefix, field string) func(*MyqState, string) interface{} {
+	diff := summary_diff(prefix, field)
+	return func(state *MyqState, id string) interface{} {
+		if picoseconds, ok := diff(state, id).(float64); ok {
+			return picoseconds / PICOSECONDS
+		}
+		return nil
+	}
+}
+
+// Average SUM_TIMER_WAIT since the last sample, in seconds
+func summary_avg(prefix string) func(*MyqState, string) interface{} {
+	count, seconds := summary_diff(prefix, `count_star`), summary_seconds(prefix, `sum_timer_wait`)
+	return func(state *MyqState, id string) interface{} {
+		n, ok := count(state, id).(float64)
+		if !ok || n == 0 {
+			return nil
+		}
+		return seconds(state, id).(float64) / n
+	}
+}
+
+// A summary field as is, on one line
+func summary_string(prefix, field string) func(*MyqState, string) interface{} {
+	return func(state *MyqState, id string) interface{} {
+		if val, err := state.Cur.getString(summaryKey(prefix, field, id)); err == nil {
+			return strings.Join(strings.Fields(val), ` `)
+		}
+		return nil
+	}
+}
+
+// The n rows that counted anything since the last sample with the most SUM_TIMER_WAIT (not reset ones)
+func top_summaries(prefix string, n int) func(*MyqState) []string {
+	latency, count := summary_diff(prefix, `sum_timer_wait`), summary_diff(prefix, `count_star`)
+	return func(state *MyqState) []string {
+		var ids []string
+		latencies := map[string]float64{}
+		for _, id := range summaryIds(state.Cur, prefix) {
+			if n, ok := count(state, id).(float64); ok && n > 0 {
+				ids = append(ids, id)
+				latencies[id] = latency(state, id).(float64)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if latencies[ids[i]] != latencies[ids[j]] {
+				return latencies[ids[i]] > latencies[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		if len(ids) > n {
+			ids = ids[:n]
+		}
+		return ids
+	}
+}
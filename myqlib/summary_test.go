@@ -0,0 +1,67 @@
+package myqlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Parse two samples of a performance_schema summary from mysql cli batch output, like a live loader's
+func summarySamples(t *testing.T, outputs []string) (MyqSample, MyqSample) {
+	ch := make(chan MyqSample, 2)
+	for _, output := range outputs {
+		parseBatch(ch, bytes.NewBufferString(output), BATCH, "")
+	}
+	prev, cur := <-ch, <-ch
+	if prev.getStr(`uptime`) != `100` || cur.getStr(`uptime`) != `101` {
+		t.Fatal("Missing status:", prev, cur)
+	}
+	return prev, cur
+}
+
+func TestSummaryViews(t *testing.T) {
+	for _, test := range []struct {
+		view     string
+		outputs  []string
+		expected [][]string // what each line has, in order
+	}{
+		{`digests`, digestOutputs, [][]string{
+			{`SELECT COUNT ( * ) FROM events`},
+			{`SELECT * FROM orders WHERE id = ?`},
+			{`SHOW GLOBAL STATUS`},
+		}},
+		{`waits`, waitOutputs, [][]string{
+			{`  200 400ms 2.0ms `, `wait/io/file/innodb/innodb_log_file`},
+			{` 100k 5.0ms  50ns `, `wait/synch/mutex/innodb/buf_pool_mutex`},
+			{`   10  20µs 2.0µs `, `wait/lock/table/sql/handler`},
+		}},
+	} {
+		prev, cur := summarySamples(t, test.outputs)
+		view := DefaultViews()[test.view]
+
+		var lines []string
+		for line := range view.Data(&MyqState{Cur: cur, Prev: prev, SecondsDiff: 1}) {
+			lines = append(lines, line)
+		}
+		if len(lines) != len(test.expected) {
+			t.Error(test.view, "expected a line for each row:", lines)
+			continue
+		}
+		for i, expected := range test.expected {
+			for _, part := range expected {
+				if !strings.Contains(lines[i], part) {
+					t.Errorf("%s: expected %q in %q", test.view, part, lines[i])
+				}
+			}
+		}
+
+		// The first sample has nothing to compare against
+		for line := range view.Data(&MyqState{Cur: prev}) {
+			for _, expected := range test.expected {
+				if last := expected[len(expected)-1]; strings.Contains(line, last) {
+					t.Error(test.view, "unexpected row without a previous sample:", line)
+				}
+			}
+		}
+	}
+}
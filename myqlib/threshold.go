@@ -0,0 +1,170 @@
+package myqlib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// How bad a value is
+const (
+	THRESHOLD_OK int = iota
+	THRESHOLD_WARN
+	THRESHOLD_CRIT
+)
+
+// ANSI colors for THRESHOLD_WARN and THRESHOLD_CRIT values
+const (
+	COLOR_WARN  string = "\x1b[33m" // yellow
+	COLOR_CRIT  string = "\x1b[31m" // red
+	COLOR_RESET string = "\x1b[0m"
+)
+
+// Only color values when the output is a terminal (see SetColor)
+var colorize bool
+
+// Turn coloring of values past their thresholds on or off
+func SetColor(on bool) {
+	colorize = on
+}
+
+// A comparison with a number, like '>100000' or '> 75%' (percents are just numbers)
+type Condition struct {
+	op    string // one of >, >=, < or <=
+	value float64
+}
+
+func ParseCondition(s string) (Condition, error) {
+	s = strings.TrimSpace(s)
+	var c Condition
+	for _, op := range []string{`>=`, `<=`, `>`, `<`} { // longest first
+		if strings.HasPrefix(s, op) {
+			c.op = op
+			break
+		}
+	}
+	if c.op == "" {
+		return c, fmt.Errorf("'%s' should start with >, >=, < or <=", s)
+	}
+
+	num := strings.TrimSuffix(strings.TrimSpace(s[len(c.op):]), `%`)
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return c, fmt.Errorf("'%s' should compare with a number", s)
+	}
+	c.value = value
+	return c, nil
+}
+
+func (c Condition) Matches(val float64) bool {
+	switch c.op {
+	case `>`:
+		return val > c.value
+	case `>=`:
+		return val >= c.value
+	case `<`:
+		return val < c.value
+	}
+	return val <= c.value
+}
+
+func (c Condition) String() string {
+	return fmt.Sprint(c.op, strconv.FormatFloat(c.value, 'f', -1, 64))
+}
+
+// Threshold Columns color the values of another column that are past the warn or crit condition
+type ThresholdCol struct {
+	ValueCol
+	warn, crit *Condition // either can be nil
+}
+
+// Wrap a column with warn and crit conditions (either can be "" for none)
+func NewThresholdCol(col ValueCol, warn, crit string) (ThresholdCol, error) {
+	tc := ThresholdCol{ValueCol: col}
+	for _, t := range []struct {
+		s    string
+		cond **Condition
+	}{{warn, &tc.warn}, {crit, &tc.crit}} {
+		if t.s == "" {
+			continue
+		}
+		c, err := ParseCondition(t.s)
+		if err != nil {
+			return tc, err
+		}
+		*t.cond = &c
+	}
+	return tc, nil
+}
+
+// For the built-in views, whose conditions can't be wrong
+func with_thresholds(col ValueCol, warn, crit string) ThresholdCol {
+	tc, err := NewThresholdCol(col, warn, crit)
+	if err != nil {
+		panic(err)
+	}
+	return tc
+}
+
+func (c ThresholdCol) Help() chan string {
+	var limits []string
+	if c.warn != nil {
+		limits = append(limits, fmt.Sprint(`warn `, c.warn))
+	}
+	if c.crit != nil {
+		limits = append(limits, fmt.Sprint(`crit `, c.crit))
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for helpst := range c.ValueCol.Help() {
+			ch <- fmt.Sprint(helpst, ` (`, strings.Join(limits, `, `), `)`)
+		}
+	}()
+	return ch
+}
+
+// THRESHOLD_OK, THRESHOLD_WARN or THRESHOLD_CRIT for the value in this state, missing values are ok
+func (c ThresholdCol) Level(state *MyqState) int {
+	val, ok := c.Value(state).(float64)
+	if !ok || !finite(val) {
+		return THRESHOLD_OK
+	}
+	return c.level(val)
+}
+
+func (c ThresholdCol) level(val float64) int {
+	if c.crit != nil && c.crit.Matches(val) {
+		return THRESHOLD_CRIT
+	} else if c.warn != nil && c.warn.Matches(val) {
+		return THRESHOLD_WARN
+	}
+	return THRESHOLD_OK
+}
+
+func (c ThresholdCol) Data(state *MyqState) chan string {
+	data := c.ValueCol.Data(state)
+	if !colorize {
+		return data
+	}
+
+	color := ""
+	switch c.Level(state) {
+	case THRESHOLD_WARN:
+		color = COLOR_WARN
+	case THRESHOLD_CRIT:
+		color = COLOR_CRIT
+	default:
+		return data
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for datast := range data {
+			ch <- fmt.Sprint(color, datast, COLOR_RESET)
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,90 @@
+package myqlib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		cond  string
+		val   float64
+		match bool
+	}{
+		{`>100000`, 100001, true},
+		{`>100000`, 100000, false},
+		{`>= 100000`, 100000, true},
+		{` > 75%`, 80, true},
+		{`<99.5`, 99.5, false},
+		{`<=99.5`, 99.5, true},
+	}
+	for _, test := range tests {
+		c, err := ParseCondition(test.cond)
+		if err != nil {
+			t.Error(test.cond, err)
+		} else if c.Matches(test.val) != test.match {
+			t.Error(test.cond, "with", test.val, "should be", test.match)
+		}
+	}
+
+	for _, bad := range []string{``, `100`, `=100`, `>`, `>lots`} {
+		if _, err := ParseCondition(bad); err == nil {
+			t.Error("Expected an error for", bad)
+		}
+	}
+}
+
+func TestThresholdCol(t *testing.T) {
+	col, err := NewThresholdCol(NewGaugeCol(`Hist`, `History List Length`, 5, `hist`, 0, NumberUnits), `>100000`, `>500000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if help := <-col.Help(); help != `Hist: History List Length (warn >100000, crit >500000)` {
+		t.Error("Unexpected help:", help)
+	}
+
+	levels := map[string]int{`10`: THRESHOLD_OK, `200000`: THRESHOLD_WARN, `600000`: THRESHOLD_CRIT, `gone`: THRESHOLD_OK}
+	for val, level := range levels {
+		if got := col.Level(&MyqState{Cur: MyqSample{`hist`: val}}); got != level {
+			t.Error(val, "expected level", level, "got", got)
+		}
+	}
+
+	state := &MyqState{Cur: MyqSample{`hist`: `600000`}}
+	if data := <-col.Data(state); data != ` 600k` {
+		t.Errorf("Expected no color by default, got %q", data)
+	}
+	SetColor(true)
+	defer SetColor(false)
+	if data := <-col.Data(state); data != "\x1b[31m 600k\x1b[0m" {
+		t.Errorf("Expected red, got %q", data)
+	}
+	if data := <-col.Data(&MyqState{Cur: MyqSample{`hist`: `10`}}); data != `   10` {
+		t.Errorf("Expected no color when ok, got %q", data)
+	}
+
+	if _, err := NewThresholdCol(col, `100`, ``); err == nil {
+		t.Error("Expected an error for a bad warn")
+	}
+}
+
+func TestFixedWidthBufferEscapes(t *testing.T) {
+	var b FixedWidthBuffer
+	b.SetWidth(8)
+	b.WriteString("ab \x1b[33mcd\x1b[0m ef\n")
+	b.WriteString("ab \x1b[31mcdefgh\x1b[0m\n")
+	b.WriteString("é\x1b[33m\x1b[0m")
+	lines := strings.Split(b.String(), "\n")
+
+	// Colors don't count towards the width
+	if lines[0] != "ab \x1b[33mcd\x1b[0m ef" {
+		t.Errorf("Expected the whole line, got %q", lines[0])
+	}
+	// Cut in the middle of a color, which is reset
+	if lines[1] != "ab \x1b[31mcdefg\x1b[0m" {
+		t.Errorf("Expected a reset after the cut, got %q", lines[1])
+	}
+	if lines[2] != "é\x1b[33m\x1b[0m" {
+		t.Errorf("Unexpected %q", lines[2])
+	}
+}
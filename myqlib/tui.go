@@ -0,0 +1,337 @@
+package myqlib
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// How many states the TUI keeps to scroll back through
+const TUI_HISTORY int = 3600
+
+// Keys (and escape sequences) the TUI knows
+const (
+	KEY_UP        string = "\x1b[A"
+	KEY_DOWN      string = "\x1b[B"
+	KEY_RIGHT     string = "\x1b[C"
+	KEY_LEFT      string = "\x1b[D"
+	KEY_PGUP      string = "\x1b[5~"
+	KEY_PGDN      string = "\x1b[6~"
+	KEY_TAB       string = "\t"
+	KEY_BACKTAB   string = "\x1b[Z"
+	KEY_INTERRUPT string = "\x03"
+)
+
+const tuiKeyHelp string = "[n/p] view  [space] pause  [+/-] aggregate  [k/j] scroll  [G] live  [q] quit"
+
+// An interactive full screen view of one stream of states.
+// Every state is kept (up to TUI_HISTORY), so switching views, aggregating
+// more or fewer samples into each row or scrolling back redraws from the history.
+// The loader's own interval never changes.
+type TUI struct {
+	views   map[string]View
+	names   []string // sorted
+	view    int      // index in names
+	timecol *Col
+
+	history []*MyqState
+	end     int               // history[:end] is on screen, the newest last
+	paused  bool              // end stays put as new states come in
+	every   int               // show every this many samples, combined into one
+	done    bool              // no more states are coming
+	notes   map[string]string // for the status line, by view
+
+	height, width int64
+}
+
+// A TUI showing the view with this name first, the timecol is set on each view as it's shown
+func NewTUI(views map[string]View, view string, timecol *Col) *TUI {
+	t := &TUI{views: views, timecol: timecol, every: 1, height: 24, width: 80}
+	for name := range views {
+		t.names = append(t.names, name)
+	}
+	sort.Strings(t.names)
+	t.view = sort.SearchStrings(t.names, view)
+	if t.view >= len(t.names) {
+		t.view = 0
+	}
+	return t
+}
+
+func (t *TUI) SetSize(height, width int64) {
+	t.height, t.width = height, width
+}
+
+// Show a note in the status line with the view, like what it needs that isn't collected
+func (t *TUI) SetNote(view, note string) {
+	if t.notes == nil {
+		t.notes = map[string]string{}
+	}
+	t.notes[view] = note
+}
+
+// Name of the view on screen
+func (t *TUI) View() string {
+	return t.names[t.view]
+}
+
+// Keep a new state, following it unless we're paused
+func (t *TUI) Add(state *MyqState) {
+	t.history = append(t.history, state)
+	if len(t.history) > TUI_HISTORY {
+		t.history = t.history[1:]
+		if t.paused && t.end > 1 {
+			t.end--
+		}
+	}
+	if !t.paused {
+		t.end = len(t.history)
+	}
+}
+
+// Act on a key, returns true if it means quit
+func (t *TUI) HandleKey(key string) bool {
+	switch key {
+	case "q", "Q", KEY_INTERRUPT:
+		return true
+	case "n", KEY_TAB, KEY_RIGHT:
+		t.view = (t.view + 1) % len(t.names)
+	case "p", KEY_BACKTAB, KEY_LEFT:
+		t.view = (t.view + len(t.names) - 1) % len(t.names)
+	case " ":
+		t.paused = !t.paused
+		if !t.paused {
+			t.end = len(t.history)
+		}
+	case "+", "=":
+		if t.every < TUI_HISTORY {
+			t.every++
+		}
+	case "-", "_":
+		if t.every > 1 {
+			t.every--
+		}
+	case "k", KEY_UP:
+		t.scroll(-1)
+	case "j", KEY_DOWN:
+		t.scroll(1)
+	case KEY_PGUP:
+		t.scroll(-t.dataHeight())
+	case KEY_PGDN:
+		t.scroll(t.dataHeight())
+	case "G":
+		t.paused = false
+		t.end = len(t.history)
+	}
+	return false
+}
+
+// Move by this many rows (of every samples each), scrolling always pauses
+func (t *TUI) scroll(rows int) {
+	if len(t.history) == 0 {
+		return
+	}
+	t.paused = true
+	t.end += rows * t.every
+	if t.end < 1 {
+		t.end = 1
+	} else if t.end > len(t.history) {
+		t.end = len(t.history)
+	}
+}
+
+// Rows left for data after the status line and (at least) a one line header
+func (t *TUI) dataHeight() int {
+	if h := int(t.height) - 2; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// The state at history[i] as if it was collected every t.every samples
+func (t *TUI) stateAt(i int) *MyqState {
+	k := t.every
+	if k > i {
+		k = i // there isn't that much history yet
+	}
+	if k <= 1 {
+		return t.history[i]
+	}
+
+	state := &MyqState{
+		Cur:         t.history[i].Cur,
+		Prev:        t.history[i-k].Cur,
+		FirstUptime: t.history[i].FirstUptime,
+	}
+	for _, s := range t.history[i-k+1 : i+1] {
+		state.SecondsDiff += s.SecondsDiff
+		state.Gap = state.Gap || s.Gap
+		if state.Annotation == "" {
+			state.Annotation = s.Annotation
+		}
+	}
+	return state
+}
+
+// Draw the whole screen, starting from the top left
+func (t *TUI) Render(w io.Writer) error {
+	var screen []string
+
+	// Show the time each row covers if we know it, files and loaders don't always sample when asked
+	every := fmt.Sprint(t.every, " samples")
+	if t.end > 0 && t.stateAt(t.end-1).SecondsDiff > 0 {
+		every = time.Duration(t.stateAt(t.end-1).SecondsDiff * float64(time.Second)).Round(time.Second).String()
+		if t.every > 1 {
+			every = fmt.Sprint(every, " (", t.every, " samples)")
+		}
+	}
+	status := fmt.Sprintf("%s (%d/%d)  every %s", t.View(), t.view+1, len(t.names), every)
+	if t.paused {
+		status = fmt.Sprint(status, fmt.Sprintf("  PAUSED at %d/%d", t.end, len(t.history)))
+	} else if t.done {
+		status = fmt.Sprint(status, "  no more samples")
+	}
+	if note := t.notes[t.View()]; note != "" {
+		status = fmt.Sprint(status, "  ", note)
+	}
+	status = fmt.Sprint(status, "  ", tuiKeyHelp)
+
+	if t.end == 0 {
+		screen = append(screen, "Waiting for the first sample...")
+	} else {
+		v := t.views[t.View()]
+		if t.timecol != nil {
+			v.SetTimeCol(t.timecol)
+		}
+
+		headers := []string{}
+		for headerln := range v.Header(t.stateAt(t.end - 1)) {
+			headers = append(headers, headerln)
+		} // headers come out in reverse order
+		for i := len(headers) - 1; i >= 0; i-- {
+			screen = append(screen, headers[i])
+		}
+
+		// Fill the rest of the screen with data, newest at the bottom
+		room := int(t.height) - len(headers) - 1
+		var rows []string
+		for i := t.end - 1; i >= 0 && len(rows) < room; i -= t.every {
+			var lines []string
+			for dataln := range v.Data(t.stateAt(i)) {
+				lines = append(lines, dataln)
+			}
+			rows = append(lines, rows...)
+		}
+		if len(rows) > room && room > 0 {
+			rows = rows[len(rows)-room:]
+		}
+		screen = append(screen, rows...)
+	}
+
+	// Raw mode needs a carriage return with every newline
+	var buf FixedWidthBuffer
+	buf.SetWidth(t.width)
+	buf.Buffer.WriteString("\x1b[H")
+	for i, line := range screen {
+		if int64(i) >= t.height-1 {
+			break
+		}
+		buf.WriteString(line)
+		buf.Buffer.WriteString("\x1b[K\r\n")
+	}
+	buf.Buffer.WriteString("\x1b[J")
+	buf.WriteString(status)
+	buf.Buffer.WriteString("\x1b[K")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Split what was read from the terminal into keys, escape sequences stay whole
+func splitKeys(b []byte) (keys []string) {
+	for len(b) > 0 {
+		n := 1
+		if b[0] == 0x1b && len(b) > 2 && b[1] == '[' {
+			// CSI sequences end with a byte in @ to ~
+			for n = 2; n < len(b) && (b[n] < 0x40 || b[n] > 0x7e); n++ {
+			}
+			if n < len(b) {
+				n++
+			}
+		} else if b[0] >= utf8.RuneSelf {
+			_, n = utf8.DecodeRune(b)
+		}
+		keys = append(keys, string(b[:n]))
+		b = b[n:]
+	}
+	return
+}
+
+// Read keys from the terminal until it closes
+func readKeys(r io.Reader) chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		b := make([]byte, 64)
+		for {
+			n, err := r.Read(b)
+			for _, key := range splitKeys(b[:n]) {
+				keys <- key
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// Take over the terminal and show states until the user quits, or there's an error from the loader
+func (t *TUI) Run(states chan *MyqState, errs chan error) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("interactive mode needs a terminal")
+	}
+	old, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, old)
+
+	// Use the alternate screen without a cursor, so the shell comes back as it was
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	resized := NotifyResize()
+	defer signal.Stop(resized)
+	t.SetSize(GetTermSize())
+
+	keys := readKeys(os.Stdin)
+	for {
+		if err := t.Render(os.Stdout); err != nil {
+			return err
+		}
+
+		select {
+		case err := <-errs:
+			return err
+		case state, ok := <-states:
+			if !ok {
+				t.done, states = true, nil // keep showing what we have
+				continue
+			}
+			t.Add(state)
+		case key, ok := <-keys:
+			if !ok || t.HandleKey(key) {
+				return nil
+			}
+		case <-resized:
+			t.SetSize(GetTermSize())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package myqlib
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadTUI(t *testing.T) *TUI {
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.byfives", "")
+	states, _, err := GetState(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tui := NewTUI(DefaultViews(), `cttf`, &Runtime_col)
+	tui.SetSize(12, 200)
+	for state := range states {
+		tui.Add(state)
+	}
+	return tui
+}
+
+func render(t *testing.T, tui *TUI) []string {
+	var buf bytes.Buffer
+	if err := tui.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(buf.String(), "\r\n")
+}
+
+func TestTUIRender(t *testing.T) {
+	tui := loadTUI(t)
+	screen := render(t, tui)
+	if len(screen) != 12 {
+		t.Fatal("Expected the whole screen, got", len(screen), "lines:", screen)
+	}
+	if !strings.HasPrefix(screen[0], "\x1b[H") || !strings.Contains(screen[0], "Connects") {
+		t.Error("Expected the cttf header first:", screen[0])
+	}
+	if status := screen[11]; !strings.Contains(status, "cttf (") || !strings.Contains(status, "every 5s") {
+		t.Error("Unexpected status line:", status)
+	}
+	if strings.Contains(screen[11], "PAUSED") {
+		t.Error("Should be following the newest sample")
+	}
+
+	// The newest sample is at the bottom
+	newest := screen[10]
+	tui.HandleKey("k")
+	if !strings.Contains(render(t, tui)[11], "PAUSED") {
+		t.Error("Scrolling should pause")
+	}
+	if render(t, tui)[10] == newest {
+		t.Error("Expected to scroll back a row")
+	}
+	if tui.HandleKey("G"); render(t, tui)[10] != newest {
+		t.Error("Expected to be back at the newest sample")
+	}
+}
+
+func TestTUIKeys(t *testing.T) {
+	tui := loadTUI(t)
+	if tui.View() != `cttf` {
+		t.Fatal("Expected to start on cttf, got", tui.View())
+	}
+	tui.HandleKey("n")
+	if tui.View() != tui.names[tui.view] || tui.names[tui.view-1] != `cttf` {
+		t.Error("Expected the next view, got", tui.View())
+	}
+	tui.HandleKey(KEY_LEFT)
+	tui.HandleKey("p")
+	if tui.View() >= `cttf` {
+		t.Error("Expected the view before cttf, got", tui.View())
+	}
+	if tui.HandleKey("x") || !tui.HandleKey("q") || !tui.HandleKey(KEY_INTERRUPT) {
+		t.Error("Only q and ^C should quit")
+	}
+
+	// Paused, new states are kept but not shown
+	tui.HandleKey(" ")
+	end := tui.end
+	tui.Add(tui.history[0])
+	if tui.end != end || len(tui.history) != end+1 {
+		t.Error("Expected to stay at", end, "got", tui.end)
+	}
+	tui.HandleKey(" ")
+	if tui.end != len(tui.history) {
+		t.Error("Expected to resume at the newest state")
+	}
+}
+
+func TestTUIInterval(t *testing.T) {
+	tui := loadTUI(t)
+	last := len(tui.history) - 1
+	one := tui.stateAt(last)
+
+	tui.HandleKey("+")
+	tui.HandleKey("+")
+	three := tui.stateAt(last)
+	if three.SecondsDiff != one.SecondsDiff*3 {
+		t.Error("Expected 3 samples of time, got", three.SecondsDiff)
+	}
+	if !reflect.DeepEqual(three.Prev, tui.history[last-3].Cur) {
+		t.Error("Expected Prev from 3 samples ago")
+	}
+	if screen := render(t, tui); !strings.Contains(screen[len(screen)-1], "every 15s (3 samples)") {
+		t.Error("Expected the aggregated time in the status line:", screen[len(screen)-1])
+	}
+
+	// Not that much history
+	if first := tui.stateAt(0); first != tui.history[0] {
+		t.Error("Expected the first state as is")
+	}
+	for i := 0; i < 5; i++ {
+		tui.HandleKey("-")
+	}
+	if tui.every != 1 {
+		t.Error("Expected every to stop at 1, got", tui.every)
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	keys := splitKeys([]byte("n\x1b[A\x1b[5~ é+\x03"))
+	expected := []string{"n", KEY_UP, KEY_PGUP, " ", "é", "+", KEY_INTERRUPT}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %q, got %q", expected, keys)
+	}
+}
+
+func TestTUINote(t *testing.T) {
+	tui := NewTUI(DefaultViews(), `repl`, nil)
+	tui.SetSize(10, 200)
+	tui.SetNote(`repl`, `(not collected, start with -repl)`)
+	if screen := render(t, tui); !strings.Contains(screen[len(screen)-1], `repl (`) || !strings.Contains(screen[len(screen)-1], `(not collected, start with -repl)`) {
+		t.Error("Expected the note in the status line:", screen[len(screen)-1])
+	}
+	tui.HandleKey("n")
+	if screen := render(t, tui); strings.Contains(screen[len(screen)-1], `not collected`) {
+		t.Error("Expected the note only with its view:", screen[len(screen)-1])
+	}
+}
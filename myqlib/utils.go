@@ -2,24 +2,35 @@ package myqlib
 
 import (
 	"bytes"
+	"golang.org/x/term"
 	"os"
 	"os/exec"
+	"os/signal"
 	"reflect"
-	"strconv"
-	"strings"
 	"syscall"
 )
 
-// this needs some error handling and testing love
+// Size of the terminal on stdout (or stdin if stdout is redirected)
 func GetTermSize() (height, width int64) {
-	cmd := exec.Command("stty", "size")
-	cmd.Stdin = os.Stdin
-	out, _ := cmd.Output()
-	vals := strings.Split(strings.TrimSpace(string(out)), " ")
-
-	height, _ = strconv.ParseInt(vals[0], 10, 64)
-	width, _ = strconv.ParseInt(vals[1], 10, 64)
-	return
+	for _, f := range []*os.File{os.Stdout, os.Stdin} {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil && h > 0 && w > 0 {
+			return int64(h), int64(w)
+		}
+	}
+
+	// Not a terminal (running as a service, for example), assume a classic one
+	return 24, 80
+}
+
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Signals on the returned channel whenever the terminal is resized, stop it with signal.Stop
+func NotifyResize() chan os.Signal {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	return resized
 }
 
 // Set OS-specific SysProcAttrs if they exist
@@ -36,7 +47,7 @@ func cleanupSubcmd(c *exec.Cmd) {
 	}
 }
 
-//
+// A buffer that truncates each string written to it to a width, ANSI escape sequences (colors) don't count towards it
 type FixedWidthBuffer struct {
 	bytes.Buffer
 	maxwidth int64
@@ -46,10 +57,44 @@ func (b *FixedWidthBuffer) SetWidth(w int64) {
 	b.maxwidth = w
 }
 func (b *FixedWidthBuffer) WriteString(s string) (n int, err error) {
-	runes := bytes.Runes([]byte(s))
-	if b.maxwidth != 0 && len(runes) > int(b.maxwidth) {
-		return b.Buffer.WriteString(string(runes[:b.maxwidth]))
-	} else {
+	if b.maxwidth == 0 {
 		return b.Buffer.WriteString(s)
 	}
+
+	var out bytes.Buffer
+	visible := int64(0)
+	escaped := false // the line has escapes, so reset them if we cut it short
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\n':
+			// Each line gets the whole width
+			out.WriteRune(runes[i])
+			visible, escaped = 0, false
+		case runes[i] == '\x1b' && i+1 < len(runes) && runes[i+1] == '[':
+			// CSI sequences end with a rune in @ to ~
+			j := i + 2
+			for j < len(runes) && (runes[j] < 0x40 || runes[j] > 0x7e) {
+				j++
+			}
+			if j == len(runes) {
+				j-- // unterminated, keep what's there
+			}
+			if visible <= b.maxwidth {
+				out.WriteString(string(runes[i : j+1]))
+				escaped = true
+			}
+			i = j
+		case visible < b.maxwidth:
+			out.WriteRune(runes[i])
+			visible++
+		case visible == b.maxwidth:
+			// Cut here, dropping the rest of the line
+			if escaped {
+				out.WriteString(COLOR_RESET)
+			}
+			visible++
+		}
+	}
+	return b.Buffer.WriteString(out.String())
 }
@@ -3,6 +3,7 @@ package myqlib
 import (
 	"fmt"
 	"regexp"
+	"unicode/utf8"
 )
 
 // Given a variable list (potentially with regexes) and a sample, expand the variables to all possible matches
@@ -28,10 +29,10 @@ func expand_variables(variables []string, sample MyqSample) (expanded []string)
 	return
 }
 
-// Fit a given string into a width
+// Fit a given string into a width (in characters, so units like µs aren't cut short)
 func fit_string(val string, width int64) string {
-	if len(val) > int(width) {
-		return val[0:width] // First width characters
+	if utf8.RuneCountInString(val) > int(width) {
+		return string([]rune(val)[0:width]) // First width characters
 	} else {
 		return fmt.Sprintf(fmt.Sprint(`%`, width, `s`), val)
 	}
@@ -49,6 +50,9 @@ func right_fit_string(val string, width int64) string {
 func column_filler(c Col) string {
 	return fit_string("-", c.Width())
 }
+func column_gap(c Col) string {
+	return fit_string("gap", c.Width())
+}
 func column_blank(c Col) string {
 	return fit_string(" ", c.Width())
 }
@@ -6,8 +6,8 @@ import (
 )
 
 func TestExpand(t *testing.T) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.single", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.single", "")
+	samples, err := l.getStatus(nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -30,8 +30,8 @@ func TestExpand(t *testing.T) {
 }
 
 func BenchmarkVariableExpand(b *testing.B) {
-	l := FileLoader{loaderInterval(1 * time.Second), "../testdata/mysqladmin.single", ""}
-	samples, err := l.getStatus()
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.single", "")
+	samples, err := l.getStatus(nil)
 	if err != nil {
 		b.Error(err)
 	}
@@ -42,3 +42,20 @@ func BenchmarkVariableExpand(b *testing.B) {
 		_ = expand_variables([]string{`com_insert.*`, `com_update.*`, `com_delete.*`, `Com_load`, `Com_replace.*`, `Com_truncate`}, sample)
 	}
 }
+
+func TestFitString(t *testing.T) {
+	tests := []struct {
+		val, expected string
+		width         int64
+	}{
+		{`abc`, `  abc`, 5},
+		{`abcdef`, `abcd`, 4},
+		{`2.0µs`, `2.0µs`, 5},
+		{`µµµ`, `µµ`, 2},
+	}
+	for _, test := range tests {
+		if got := fit_string(test.val, test.width); got != test.expected {
+			t.Errorf("%q in %d expected %q got %q", test.val, test.width, test.expected, got)
+		}
+	}
+}
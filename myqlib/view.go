@@ -20,6 +20,9 @@ type View interface {
 
 	// All the cols (including time col)
 	all_cols() []Col
+
+	// The time col (nil if there isn't one)
+	time_col() *Col
 }
 
 // NormalView
@@ -120,6 +123,10 @@ func (v *NormalView) all_cols() []Col {
 	}
 }
 
+func (v *NormalView) time_col() *Col {
+	return v.timecol
+}
+
 func (v *NormalView) Width() (w int64) {
 	for _, col := range v.all_cols() {
 		w += col.Width() + 1
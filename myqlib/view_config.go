@@ -0,0 +1,198 @@
+package myqlib
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+)
+
+// Where user views are loaded from if no other file is given (relative to $HOME)
+const DEFAULT_VIEWFILE string = ".myq_views.yaml"
+
+// A file of user views looks like:
+//
+//	views:
+//	  bp:
+//	    help: Buffer pool activity
+//	    cols:
+//	      - group: Buffer Pool
+//	        help: Buffer Pool Stats
+//	        cols:
+//	          - {kind: gauge, name: data, help: Data Buffered, width: 5, variable: innodb_buffer_pool_bytes_data, units: memory}
+//	          - {kind: percent, name: dirt, help: Buffer pool %dirty, width: 4, variables: [innodb_buffer_pool_pages_dirty, innodb_buffer_pool_pages_total]}
+//	      - {kind: ratesum, name: dml, help: Row changes / s, width: 5, variables: ['innodb_rows_(inserted|updated|deleted)']}
+//	      - {kind: expr, name: hit, help: Hit ratio, width: 5, expr: '100 * (1 - rate(innodb_buffer_pool_reads)/rate(innodb_buffer_pool_read_requests))', precision: 1, units: percent, warn: '<99', crit: '<95'}
+//
+// Any column can have a warn and/or crit condition (like '>100000' or '> 75%') to color its values when they're past it.
+type viewsConfig struct {
+	Views map[string]viewConfig `yaml:"views"`
+}
+
+type viewConfig struct {
+	Help string      `yaml:"help"`
+	Cols []colConfig `yaml:"cols"`
+}
+
+// Either a column (with a kind) or a group of columns (with a title in group)
+type colConfig struct {
+	Kind      string      `yaml:"kind"`
+	Group     string      `yaml:"group"`
+	Name      string      `yaml:"name"`
+	Help      string      `yaml:"help"`
+	Width     int64       `yaml:"width"`
+	Variable  string      `yaml:"variable"`
+	Variables []string    `yaml:"variables"`
+	Expr      string      `yaml:"expr"`
+	Precision int64       `yaml:"precision"`
+	Units     string      `yaml:"units"`
+	Warn      string      `yaml:"warn"`
+	Crit      string      `yaml:"crit"`
+	Cols      []colConfig `yaml:"cols"`
+}
+
+// Units by the name they have in a view file
+var unitsByName = map[string]UnitsDef{
+	``:            NumberUnits,
+	`number`:      NumberUnits,
+	`memory`:      MemoryUnits,
+	`second`:      SecondUnits,
+	`microsecond`: MicroSecondUnits,
+	`nanosecond`:  NanoSecondUnits,
+	`percent`:     PercentUnits,
+}
+
+// Load the views in a YAML view file, these can be merged with (or override) the DefaultViews()
+func LoadViews(filename string) (map[string]View, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseViews(data)
+}
+
+func parseViews(data []byte) (map[string]View, error) {
+	var config viewsConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, err
+	}
+
+	views := map[string]View{}
+	for name, vc := range config.Views {
+		if len(vc.Cols) == 0 {
+			return nil, fmt.Errorf("view %s: no cols", name)
+		}
+		var cols []Col
+		for _, cc := range vc.Cols {
+			var col Col
+			var err error
+			if cc.Group != "" {
+				col, err = cc.newGroupCol()
+			} else {
+				col, err = cc.newCol()
+			}
+			if err != nil {
+				return nil, fmt.Errorf("view %s: %s", name, err)
+			}
+			cols = append(cols, col)
+		}
+		views[name] = NewNormalView(vc.Help, cols...)
+	}
+	return views, nil
+}
+
+func (cc colConfig) newGroupCol() (Col, error) {
+	if len(cc.Cols) == 0 {
+		return nil, fmt.Errorf("group %s: no cols", cc.Group)
+	}
+	var cols []Col
+	for _, gcc := range cc.Cols {
+		if gcc.Group != "" {
+			return nil, fmt.Errorf("group %s: groups can't contain groups", cc.Group)
+		}
+		col, err := gcc.newCol()
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %s", cc.Group, err)
+		}
+		cols = append(cols, col)
+	}
+	return NewGroupCol(cc.Group, cc.Help, cols...), nil
+}
+
+// Build the column, with thresholds if it has any
+func (cc colConfig) newCol() (Col, error) {
+	col, err := cc.newKindCol()
+	if err != nil || (cc.Warn == "" && cc.Crit == "") {
+		return col, err
+	}
+	vc, ok := col.(ValueCol)
+	if !ok {
+		return nil, fmt.Errorf("column %s: %s columns can't have thresholds", cc.Name, cc.Kind)
+	}
+	tc, err := NewThresholdCol(vc, cc.Warn, cc.Crit)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %s", cc.Name, err)
+	}
+	return tc, nil
+}
+
+// Build the column of the configured kind
+func (cc colConfig) newKindCol() (Col, error) {
+	if cc.Name == "" {
+		return nil, fmt.Errorf("%s column without a name", cc.Kind)
+	}
+	units, ok := unitsByName[cc.Units]
+	if !ok {
+		return nil, fmt.Errorf("column %s: unknown units '%s'", cc.Name, cc.Units)
+	}
+	width := cc.Width
+	if width <= 0 {
+		width = int64(len(cc.Name)) // just fit the header
+	}
+
+	// Check the variables the kind needs
+	need := func(n int) error {
+		if n == 1 && cc.Variable == "" {
+			return fmt.Errorf("column %s: %s needs a variable", cc.Name, cc.Kind)
+		} else if n > 1 && len(cc.Variables) != n {
+			return fmt.Errorf("column %s: %s needs %d variables", cc.Name, cc.Kind, n)
+		} else if n == 0 && len(cc.Variables) == 0 {
+			return fmt.Errorf("column %s: %s needs variables", cc.Name, cc.Kind)
+		}
+		return nil
+	}
+
+	switch cc.Kind {
+	case `gauge`:
+		return NewGaugeCol(cc.Name, cc.Help, width, cc.Variable, cc.Precision, units), need(1)
+	case `rate`:
+		return NewRateCol(cc.Name, cc.Help, width, cc.Variable, cc.Precision, units), need(1)
+	case `diff`:
+		return NewDiffCol(cc.Name, cc.Help, width, cc.Variable, cc.Precision, units), need(1)
+	case `string`:
+		return NewStringCol(cc.Name, cc.Help, width, cc.Variable), need(1)
+	case `rightmost`:
+		return NewRightmostCol(cc.Name, cc.Help, width, cc.Variable), need(1)
+	case `percent`:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		return NewPercentCol(cc.Name, cc.Help, width, cc.Variables[0], cc.Variables[1], cc.Precision), nil
+	case `curdiff`:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		return NewCurDiffCol(cc.Name, cc.Help, width, cc.Variables[0], cc.Variables[1], cc.Precision, units), nil
+	case `ratesum`:
+		return NewRateSumCol(cc.Name, cc.Help, width, cc.Precision, units, cc.Variables...), need(0)
+	case `expr`:
+		if cc.Expr == "" {
+			return nil, fmt.Errorf("column %s: expr needs an expr", cc.Name)
+		}
+		col, err := NewExprCol(cc.Name, cc.Help, width, cc.Expr, cc.Precision, units)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %s", cc.Name, err)
+		}
+		return col, nil
+	}
+	return nil, fmt.Errorf("column %s: unknown kind '%s'", cc.Name, cc.Kind)
+}
@@ -0,0 +1,61 @@
+package myqlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadViews(t *testing.T) {
+	views, err := LoadViews("../testdata/views.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 {
+		t.Fatal("Expected 2 views, got", len(views))
+	}
+
+	v := views[`bp`]
+	l := NewFileLoader(1*time.Second, "../testdata/mysqladmin.two", "")
+	v.SetTimeCol(&Runtime_col)
+	lines := viewOutput(t, l, v)
+	if len(lines) != 2 {
+		t.Fatal("Expected 2 lines, got", len(lines))
+	}
+	if lines[1] != `      1s 63.7G   1%  862k  5715 15.9m   86  100%` {
+		t.Errorf("Unexpected output: '%s'", lines[1])
+	}
+
+	var headers []string
+	for header := range v.Header(&MyqState{}) {
+		headers = append(headers, header)
+	}
+	if strings.TrimSpace(headers[1]) != `Buffer Pool` {
+		t.Errorf("Unexpected group header: '%s'", headers[1])
+	}
+
+	var help []string
+	for line := range views[`temp`].Help() {
+		help = append(help, line)
+	}
+	if help[0] != `Temporary tables (just disk)` || help[1] != "\tdisk: On Disk Temp Tables / second" {
+		t.Error("Unexpected help:", help)
+	}
+}
+
+func TestBadViews(t *testing.T) {
+	for config, expected := range map[string]string{
+		`views: {x: {cols: [{kind: gauge, name: a}]}}`:                          `view x: column a: gauge needs a variable`,
+		`views: {x: {cols: [{kind: bogus, name: a, variable: b}]}}`:             `view x: column a: unknown kind 'bogus'`,
+		`views: {x: {cols: [{kind: rate, name: a, variable: b, units: feet}]}}`: `view x: column a: unknown units 'feet'`,
+		`views: {x: {cols: [{kind: percent, name: a, variables: [b]}]}}`:        `view x: column a: percent needs 2 variables`,
+		`views: {x: {cols: [{group: g, cols: [{group: h}]}]}}`:                  `view x: group g: groups can't contain groups`,
+		`views: {x: {cols: [{kind: expr, name: a, expr: 'rate(b'}]}}`:           `view x: column a: bad expression 'rate(b' at 7: missing ')'`,
+		`views: {x: {help: nothing}}`:                                           `view x: no cols`,
+		`views: {x: {cols: [{kind: rate, nmae: a}]}}`:                           `field nmae not found`,
+	} {
+		if _, err := parseViews([]byte(config)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected '%s' for %s, got %v", expected, config, err)
+		}
+	}
+}
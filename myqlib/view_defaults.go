@@ -11,21 +11,63 @@ import (
 
 // Time Columns
 var (
-	Timestamp_col Col = NewFuncCol(`time`, `Time data was printed`, 8,
+	Timestamp_col Col = NewFuncValueCol(`time`, `Time data was printed`, 8,
 		func(state *MyqState, c Col) chan string {
 			ch := make(chan string, 1)
 			defer close(ch)
-			ch <- fit_string(time.Now().Format(`15:04:05`), c.Width())
+			if state.Annotation != "" {
+				ch <- fit_string(state.Annotation, c.Width())
+			} else {
+				ch <- fit_string(time.Now().Format(`15:04:05`), c.Width())
+			}
 			return ch
+		},
+		func(state *MyqState) interface{} {
+			// Live samples are usually stamped when they were collected
+			if ts, err := state.Cur.getTimestamp(); err == nil {
+				return ts
+			}
+			return time.Now()
 		})
 
-	Runtime_col Col = NewFuncCol(`time`, `Interval since data started`, 8,
+	Runtime_col Col = NewFuncValueCol(`time`, `Interval since data started`, 8,
 		func(state *MyqState, c Col) chan string {
 			ch := make(chan string, 1)
 			defer close(ch)
-			runtime := time.Duration(state.Cur.getI(`uptime`)-state.FirstUptime) * time.Second
-			ch <- fit_string(fmt.Sprintf("%.0fs", runtime.Seconds()), c.Width())
+			if state.Annotation != "" {
+				ch <- fit_string(state.Annotation, c.Width())
+			} else {
+				runtime := time.Duration(state.Cur.getI(`uptime`)-state.FirstUptime) * time.Second
+				ch <- fit_string(fmt.Sprintf("%.0fs", runtime.Seconds()), c.Width())
+			}
 			return ch
+		},
+		func(state *MyqState) interface{} {
+			return float64(state.Cur.getI(`uptime`) - state.FirstUptime)
+		})
+
+	// Falls back to the Runtime_col if the sample has no timestamp
+	Capturetime_col Col = NewFuncValueCol(`time`, `Time data was captured`, 8,
+		func(state *MyqState, c Col) chan string {
+			ts, err := state.Cur.getTimestamp()
+			if err != nil {
+				return Runtime_col.Data(state)
+			}
+
+			ch := make(chan string, 1)
+			defer close(ch)
+			if state.Annotation != "" {
+				ch <- fit_string(state.Annotation, c.Width())
+			} else {
+				ch <- fit_string(ts.Format(`15:04:05`), c.Width())
+			}
+			return ch
+		},
+		func(state *MyqState) interface{} {
+			if ts, err := state.Cur.getTimestamp(); err == nil {
+				return ts
+			}
+			return Runtime_col.(ValueCol).Value(state)
 		})
 )
 
@@ -39,7 +81,7 @@ func DefaultViews() map[string]View {
 			),
 			NewGroupCol(`Threads`, `Thread related metrics`,
 				NewGaugeCol(`conn`, `Threads Connected`, 4, `threads_connected`, 0, NumberUnits),
-				NewGaugeCol(`run`, `Threads running`, 4, `threads_running`, 0, NumberUnits),
+				with_thresholds(NewGaugeCol(`run`, `Threads running`, 4, `threads_running`, 0, NumberUnits), `>32`, `>64`),
 				NewGaugeCol(`cach`, `Threads Cached`, 4, `threads_cached`, 0, NumberUnits),
 				NewRateCol(`crtd`, `Threads Created per second`, 4, `threads_created`, 0, NumberUnits),
 				NewRateCol(`slow`, `Threads that were slow to launch per second`, 4, `slow_launch_threads`, 0, NumberUnits),
@@ -129,7 +171,7 @@ func DefaultViews() map[string]View {
 			),
 			NewGroupCol(`Buffer Pool`, `Buffer Pool Stats`,
 				NewGaugeCol(`data`, `Data Buffered`, 5, `innodb_buffer_pool_bytes_data`, 0, MemoryUnits),
-				NewPercentCol(`dirt`, `Buffer pool %dirty`, 4, `innodb_buffer_pool_pages_dirty`, `innodb_buffer_pool_pages_total`, 0),
+				with_thresholds(NewPercentCol(`dirt`, `Buffer pool %dirty`, 4, `innodb_buffer_pool_pages_dirty`, `innodb_buffer_pool_pages_total`, 0), `>75%`, `>90%`),
 				NewRateCol(`rreq`, `Read Requests (Logical) / s`, 5, `innodb_buffer_pool_read_requests`, 0, NumberUnits),
 				NewRateCol(`read`, `Reads (Physical) / s`, 4, `innodb_buffer_pool_reads`, 0, NumberUnits),
 				NewRateCol(`wreq`, `Write Requests / s`, 5, `innodb_buffer_pool_write_requests`, 0, NumberUnits),
@@ -137,14 +179,14 @@ func DefaultViews() map[string]View {
 			),
 			NewGroupCol(`Log`, `Log Information`,
 				NewGaugeCol(`Chkpt`, `Checkpoint age`, 5, `innodb_checkpoint_age`, 0, MemoryUnits),
-				NewPercentCol(`%`, `% of Checkpoint age target`, 4, `innodb_checkpoint_age`, `innodb_checkpoint_max_age`, 0),
+				with_thresholds(NewPercentCol(`%`, `% of Checkpoint age target`, 4, `innodb_checkpoint_age`, `innodb_checkpoint_max_age`, 0), `>75%`, `>90%`),
 				NewRateCol(`lsn`, `Log growth (LSN)`, 5, `innodb_lsn_current`, 0, MemoryUnits),
 			),
 			NewGroupCol(`Data`, `Data Operations`,
 				NewRateCol(`read`, `Bytes Read / s`, 5, `innodb_data_read`, 0, MemoryUnits),
 				NewRateCol(`writes`, `Bytes Written / s`, 5, `innodb_data_written`, 0, MemoryUnits),
 			),
-			NewGaugeCol(`Hist`, `History List Length`, 5, `innodb_history_list_length`, 0, NumberUnits),
+			with_thresholds(NewGaugeCol(`Hist`, `History List Length`, 5, `innodb_history_list_length`, 0, NumberUnits), `>100000`, `>500000`),
 		),
 		`innodb_buffer_pool`: NewNormalView(`Innodb Buffer Pool stats`,
 			NewGroupCol(`Buffer Pool Pages`, `Innodb Buffer Pool Pages stats`,
@@ -177,7 +219,7 @@ func DefaultViews() map[string]View {
 		),
 		`innodb_flush`: NewNormalView(`Innodb flushing metrics`,
 			NewGroupCol(`Pages`, `Checkpoint info`,
-				NewPercentCol(`dirt`, `Buffer pool %dirty`, 4, `innodb_buffer_pool_pages_dirty`, `innodb_buffer_pool_pages_total`, 0),
+				with_thresholds(NewPercentCol(`dirt`, `Buffer pool %dirty`, 4, `innodb_buffer_pool_pages_dirty`, `innodb_buffer_pool_pages_total`, 0), `>75%`, `>90%`),
 				NewRateCol(`flush`, `All pages flushed`, 5, `innodb_buffer_pool_pages_flushed`, 0, NumberUnits),
 				NewRateCol(`lruf`, `LRU flushes`, 5, `innodb_buffer_pool_pages_lru_flushed`, 0, NumberUnits),
 			),
@@ -214,7 +256,7 @@ func DefaultViews() map[string]View {
 			),
 			NewGroupCol(`Node`, `Node's specific state`,
 				// NewStringCol(`state`, `State of this node`, 4, `wsrep_local_state_comment`),
-				NewFuncCol(`state`, `State of this node`, 4, func(state *MyqState, c Col) chan string {
+				NewFuncValueCol(`state`, `State of this node`, 4, func(state *MyqState, c Col) chan string {
 					ch := make( chan string, 1)
 					defer close(ch)
 
@@ -243,9 +285,11 @@ func DefaultViews() map[string]View {
 					}
 
 					return ch
+				}, func(state *MyqState) interface{} {
+					return state.Cur.getStr(`wsrep_local_state_comment`)
 				}),
 			),
-			NewFuncCol(`laten`, `Average replication latency`, 5, func(state *MyqState, c Col) chan string {
+			NewFuncValueCol(`laten`, `Average replication latency`, 5, func(state *MyqState, c Col) chan string {
 				ch := make(chan string, 1)
 				defer close(ch)
 				vals := strings.Split(state.Cur.getStr(`wsrep_evs_repl_latency`), `/`)
@@ -262,6 +306,14 @@ func DefaultViews() map[string]View {
 					}
 				}
 				return ch
+			}, func(state *MyqState) interface{} {
+				vals := strings.Split(state.Cur.getStr(`wsrep_evs_repl_latency`), `/`)
+				if len(vals) == 5 {
+					if avg, err := strconv.ParseFloat(vals[1], 64); err == nil {
+						return avg
+					}
+				}
+				return nil
 			}),
 			NewGroupCol(`Outbound`, `Sent replication events`,
 				NewRateCol(`msgs`, `Replicated messages (usually transactions) per second`, 4, `wsrep_replicated`, 0, NumberUnits),
@@ -289,6 +341,10 @@ func DefaultViews() map[string]View {
 				NewPercentCol(`%ef`, `Percent of threads being used`, 4, `wsrep_apply_window`, `V_wsrep_slave_threads`, 0),
 			),
 		),
+		`repl`:          NewNormalView(`Replication status of every channel (collected with -repl)`, replication_cols()...),
+		`innodb_status`: NewNormalView(`SHOW ENGINE INNODB STATUS semaphores, transactions and deadlocks (collected with -innodbstatus)`, innodb_status_cols()...),
+		`digests`:       NewNormalView(`Top statement digests by latency since the last sample, from performance_schema (collected with -digests)`, digest_cols()...),
+		`waits`:         NewNormalView(`Top performance_schema wait classes (file IO, mutexes, locks) by wait time since the last sample (collected with -waits)`, wait_cols()...),
 		`qcache`: NewNormalView(`Query cache stats`,
 			NewStringCol(`type`, `Query cache type`, 6, `V_query_cache_type`),
 			NewRateSumCol(`sel`, `Total Selects + Qcache Hits per second`, 4, 0, NumberUnits, `com_select`, `qcache_hits`),
@@ -313,6 +369,13 @@ func DefaultViews() map[string]View {
 		),
 		`commands`: NewNormalView(`Sorted list of all commands run in a given interval`,
 			NewFuncCol(`Counts`, `All commands tracked by the Com_* counters`, 4, func(state *MyqState, c Col) chan string {
+				if state.Gap { // nothing to compare against
+					ch := make(chan string, 1)
+					defer close(ch)
+					ch <- column_gap(c)
+					return ch
+				}
+
 				var all_diffs []float64
 				diff_variables := map[float64][]string{}
 
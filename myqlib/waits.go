@@ -0,0 +1,54 @@
+package myqlib
+
+import (
+	"strings"
+)
+
+const (
+	// Wait events by class, cumulative since the server started.  Idle is waiting for the client, not contention.
+	WAITS_QUERY string = "SELECT EVENT_NAME, COUNT_STAR, SUM_TIMER_WAIT FROM performance_schema.events_waits_summary_global_by_event_name WHERE COUNT_STAR > 0 AND EVENT_NAME != 'idle'"
+
+	// prefix of wait keys in a sample: wait_<field>.<event name>, like wait_count_star.wait/io/file/innodb/innodb_log_file
+	WAIT_PREFIX = "wait_"
+
+	// How many wait classes the waits view shows each sample
+	WAIT_TOP_N = 10
+)
+
+// performance_schema wait event summaries for live loaders to collect too
+var WaitsQuery = ExtraQuery{query: WAITS_QUERY, add: addWait}
+
+// The key of a wait class field in a sample
+func waitKey(field, event string) string {
+	return summaryKey(WAIT_PREFIX, field, event)
+}
+
+// Whether a vertical row is a wait class
+func isWaitRow(row map[string]string) bool {
+	fields := map[string]bool{}
+	for name := range row {
+		fields[strings.ToLower(name)] = true
+	}
+	return fields[`event_name`] && fields[`count_star`]
+}
+
+// Add one row of events_waits_summary_global_by_event_name to a sample
+func addWait(sample MyqSample, row map[string]string) {
+	for name, value := range row {
+		if strings.ToLower(name) == `event_name` && value != "" {
+			addSummary(sample, WAIT_PREFIX, value, row)
+			return
+		}
+	}
+}
+
+// The waits view's columns
+func wait_cols() []Col {
+	top := top_summaries(WAIT_PREFIX, WAIT_TOP_N)
+	return []Col{
+		NewTopCol(`cnt`, `Waits since the last sample`, 5, 0, NumberUnits, top, summary_diff(WAIT_PREFIX, `count_star`)),
+		NewTopCol(`lat`, `Total wait time since the last sample`, 5, 0, SecondUnits, top, summary_seconds(WAIT_PREFIX, `sum_timer_wait`)),
+		NewTopCol(`avg`, `Average wait since the last sample`, 5, 0, SecondUnits, top, summary_avg(WAIT_PREFIX)),
+		NewTopCol(`event`, `Wait class, like wait/io/file/*, wait/synch/mutex/* or wait/lock/*`, 50, 0, NumberUnits, top, summary_string(WAIT_PREFIX, `event_name`)),
+	}
+}
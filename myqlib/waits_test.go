@@ -0,0 +1,61 @@
+package myqlib
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Two samples of wait classes from a live server in vertical output, before the status
+var waitOutputs = []string{`*************************** 1. row ***************************
+    EVENT_NAME: wait/io/file/innodb/innodb_log_file
+    COUNT_STAR: 5000
+SUM_TIMER_WAIT: 10000000000000
+*************************** 2. row ***************************
+    EVENT_NAME: wait/synch/mutex/innodb/buf_pool_mutex
+    COUNT_STAR: 900000
+SUM_TIMER_WAIT: 45000000000
+Uptime	100
+`, `*************************** 1. row ***************************
+    EVENT_NAME: wait/io/file/innodb/innodb_log_file
+    COUNT_STAR: 5200
+SUM_TIMER_WAIT: 10400000000000
+*************************** 2. row ***************************
+    EVENT_NAME: wait/synch/mutex/innodb/buf_pool_mutex
+    COUNT_STAR: 1000000
+SUM_TIMER_WAIT: 50000000000
+*************************** 3. row ***************************
+    EVENT_NAME: wait/lock/table/sql/handler
+    COUNT_STAR: 10
+SUM_TIMER_WAIT: 20000000
+Uptime	101
+`}
+
+func TestWaits(t *testing.T) {
+	prev, cur := summarySamples(t, waitOutputs)
+	if got := cur.getStr(waitKey(`count_star`, `wait/lock/table/sql/handler`)); got != `10` {
+		t.Error("Unexpected count:", got)
+	}
+	if got := cur.getStr(`repl_event_name`); got != `` {
+		t.Error("Waits leaked into replication status:", got)
+	}
+
+	state := &MyqState{Cur: cur, Prev: prev, SecondsDiff: 1}
+	expected := []string{`wait/io/file/innodb/innodb_log_file`, `wait/synch/mutex/innodb/buf_pool_mutex`, `wait/lock/table/sql/handler`}
+	if events := top_summaries(WAIT_PREFIX, WAIT_TOP_N)(state); !reflect.DeepEqual(events, expected) {
+		t.Error("Unexpected top waits:", events)
+	}
+
+	// 400ms over 200 log file waits, 5ms over 100000 mutex waits
+	// In seconds, like every other time
+	avg := summary_avg(WAIT_PREFIX)
+	if got := avg(state, `wait/io/file/innodb/innodb_log_file`).(float64); math.Abs(got-0.002) > 1e-12 {
+		t.Error("Unexpected average log file wait:", got)
+	}
+	if got := avg(state, `wait/synch/mutex/innodb/buf_pool_mutex`).(float64); math.Abs(got-50e-9) > 1e-15 {
+		t.Error("Unexpected average mutex wait:", got)
+	}
+	if avg := avg(&MyqState{Cur: cur, Prev: cur}, `wait/lock/table/sql/handler`); avg != nil {
+		t.Error("Expected no average without waits:", avg)
+	}
+}
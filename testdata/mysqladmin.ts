@@ -0,0 +1,926 @@
+TS 1414170880.005 2014-10-24 13:34:40
++-----------------------------------------------+----------------------------------------------------+
+| Variable_name                                 | Value                                              |
++-----------------------------------------------+----------------------------------------------------+
+| Aborted_clients                               | 3                                                  |
+| Aborted_connects                              | 21265                                              |
+| Binlog_snapshot_file                          | binlog.002444                                      |
+| Binlog_snapshot_position                      | 596425079                                          |
+| Binlog_cache_disk_use                         | 3146                                               |
+| Binlog_cache_use                              | 3119742                                            |
+| Binlog_stmt_cache_disk_use                    | 0                                                  |
+| Binlog_stmt_cache_use                         | 0                                                  |
+| Bytes_received                                | 19095555804                                        |
+| Bytes_sent                                    | 145079721601                                       |
+| Com_admin_commands                            | 32253                                              |
+| Com_assign_to_keycache                        | 0                                                  |
+| Com_alter_db                                  | 0                                                  |
+| Com_alter_db_upgrade                          | 0                                                  |
+| Com_alter_event                               | 0                                                  |
+| Com_alter_function                            | 0                                                  |
+| Com_alter_procedure                           | 0                                                  |
+| Com_alter_server                              | 0                                                  |
+| Com_alter_table                               | 0                                                  |
+| Com_alter_tablespace                          | 0                                                  |
+| Com_alter_user                                | 0                                                  |
+| Com_analyze                                   | 0                                                  |
+| Com_begin                                     | 4000469                                            |
+| Com_binlog                                    | 0                                                  |
+| Com_call_procedure                            | 0                                                  |
+| Com_change_db                                 | 12475                                              |
+| Com_change_master                             | 0                                                  |
+| Com_check                                     | 0                                                  |
+| Com_checksum                                  | 0                                                  |
+| Com_commit                                    | 3999763                                            |
+| Com_create_db                                 | 0                                                  |
+| Com_create_event                              | 0                                                  |
+| Com_create_function                           | 0                                                  |
+| Com_create_index                              | 0                                                  |
+| Com_create_procedure                          | 0                                                  |
+| Com_create_server                             | 0                                                  |
+| Com_create_table                              | 0                                                  |
+| Com_create_trigger                            | 0                                                  |
+| Com_create_udf                                | 0                                                  |
+| Com_create_user                               | 0                                                  |
+| Com_create_view                               | 0                                                  |
+| Com_dealloc_sql                               | 0                                                  |
+| Com_delete                                    | 216524                                             |
+| Com_delete_multi                              | 0                                                  |
+| Com_do                                        | 0                                                  |
+| Com_drop_db                                   | 0                                                  |
+| Com_drop_event                                | 0                                                  |
+| Com_drop_function                             | 0                                                  |
+| Com_drop_index                                | 0                                                  |
+| Com_drop_procedure                            | 0                                                  |
+| Com_drop_server                               | 0                                                  |
+| Com_drop_table                                | 0                                                  |
+| Com_drop_trigger                              | 0                                                  |
+| Com_drop_user                                 | 0                                                  |
+| Com_drop_view                                 | 0                                                  |
+| Com_empty_query                               | 0                                                  |
+| Com_execute_sql                               | 0                                                  |
+| Com_flush                                     | 1863                                               |
+| Com_get_diagnostics                           | 0                                                  |
+| Com_grant                                     | 0                                                  |
+| Com_ha_close                                  | 0                                                  |
+| Com_ha_open                                   | 0                                                  |
+| Com_ha_read                                   | 0                                                  |
+| Com_help                                      | 1                                                  |
+| Com_insert                                    | 2569052                                            |
+| Com_insert_select                             | 0                                                  |
+| Com_install_plugin                            | 0                                                  |
+| Com_kill                                      | 0                                                  |
+| Com_load                                      | 0                                                  |
+| Com_lock_tables                               | 0                                                  |
+| Com_lock_tables_for_backup                    | 0                                                  |
+| Com_lock_binlog_for_backup                    | 0                                                  |
+| Com_optimize                                  | 0                                                  |
+| Com_preload_keys                              | 0                                                  |
+| Com_prepare_sql                               | 0                                                  |
+| Com_purge                                     | 0                                                  |
+| Com_purge_before_date                         | 0                                                  |
+| Com_purge_archived                            | 0                                                  |
+| Com_purge_archived_before_date                | 0                                                  |
+| Com_release_savepoint                         | 0                                                  |
+| Com_rename_table                              | 0                                                  |
+| Com_rename_user                               | 0                                                  |
+| Com_repair                                    | 0                                                  |
+| Com_replace                                   | 0                                                  |
+| Com_replace_select                            | 0                                                  |
+| Com_reset                                     | 0                                                  |
+| Com_resignal                                  | 0                                                  |
+| Com_revoke                                    | 0                                                  |
+| Com_revoke_all                                | 0                                                  |
+| Com_rollback                                  | 701                                                |
+| Com_rollback_to_savepoint                     | 0                                                  |
+| Com_savepoint                                 | 0                                                  |
+| Com_select                                    | 49706085                                           |
+| Com_set_option                                | 303610                                             |
+| Com_signal                                    | 0                                                  |
+| Com_show_binlog_events                        | 0                                                  |
+| Com_show_binlogs                              | 0                                                  |
+| Com_show_charsets                             | 0                                                  |
+| Com_show_client_statistics                    | 0                                                  |
+| Com_show_collations                           | 0                                                  |
+| Com_show_create_db                            | 0                                                  |
+| Com_show_create_event                         | 0                                                  |
+| Com_show_create_func                          | 0                                                  |
+| Com_show_create_proc                          | 0                                                  |
+| Com_show_create_table                         | 0                                                  |
+| Com_show_create_trigger                       | 0                                                  |
+| Com_show_databases                            | 0                                                  |
+| Com_show_engine_logs                          | 0                                                  |
+| Com_show_engine_mutex                         | 0                                                  |
+| Com_show_engine_status                        | 0                                                  |
+| Com_show_events                               | 0                                                  |
+| Com_show_errors                               | 0                                                  |
+| Com_show_fields                               | 0                                                  |
+| Com_show_function_code                        | 0                                                  |
+| Com_show_function_status                      | 0                                                  |
+| Com_show_grants                               | 0                                                  |
+| Com_show_index_statistics                     | 0                                                  |
+| Com_show_keys                                 | 0                                                  |
+| Com_show_master_status                        | 1687                                               |
+| Com_show_open_tables                          | 0                                                  |
+| Com_show_plugins                              | 1                                                  |
+| Com_show_privileges                           | 0                                                  |
+| Com_show_procedure_code                       | 0                                                  |
+| Com_show_procedure_status                     | 0                                                  |
+| Com_show_processlist                          | 10121                                              |
+| Com_show_profile                              | 0                                                  |
+| Com_show_profiles                             | 0                                                  |
+| Com_show_relaylog_events                      | 0                                                  |
+| Com_show_slave_hosts                          | 0                                                  |
+| Com_show_slave_status                         | 1687                                               |
+| Com_show_slave_status_nolock                  | 0                                                  |
+| Com_show_status                               | 72591                                              |
+| Com_show_storage_engines                      | 0                                                  |
+| Com_show_table_statistics                     | 0                                                  |
+| Com_show_table_status                         | 0                                                  |
+| Com_show_tables                               | 282649                                             |
+| Com_show_thread_statistics                    | 0                                                  |
+| Com_show_triggers                             | 0                                                  |
+| Com_show_user_statistics                      | 0                                                  |
+| Com_show_variables                            | 4753                                               |
+| Com_show_warnings                             | 0                                                  |
+| Com_slave_start                               | 0                                                  |
+| Com_slave_stop                                | 0                                                  |
+| Com_stmt_close                                | 0                                                  |
+| Com_stmt_execute                              | 0                                                  |
+| Com_stmt_fetch                                | 0                                                  |
+| Com_stmt_prepare                              | 0                                                  |
+| Com_stmt_reprepare                            | 0                                                  |
+| Com_stmt_reset                                | 0                                                  |
+| Com_stmt_send_long_data                       | 0                                                  |
+| Com_truncate                                  | 0                                                  |
+| Com_uninstall_plugin                          | 0                                                  |
+| Com_unlock_binlog                             | 0                                                  |
+| Com_unlock_tables                             | 0                                                  |
+| Com_update                                    | 3662304                                            |
+| Com_update_multi                              | 0                                                  |
+| Com_xa_commit                                 | 0                                                  |
+| Com_xa_end                                    | 0                                                  |
+| Com_xa_prepare                                | 0                                                  |
+| Com_xa_recover                                | 0                                                  |
+| Com_xa_rollback                               | 0                                                  |
+| Com_xa_start                                  | 0                                                  |
+| Compression                                   | OFF                                                |
+| Connection_errors_accept                      | 0                                                  |
+| Connection_errors_internal                    | 0                                                  |
+| Connection_errors_max_connections             | 0                                                  |
+| Connection_errors_peer_address                | 0                                                  |
+| Connection_errors_select                      | 0                                                  |
+| Connection_errors_tcpwrap                     | 0                                                  |
+| Connections                                   | 375649                                             |
+| Created_tmp_disk_tables                       | 2543159                                            |
+| Created_tmp_files                             | 1221                                               |
+| Created_tmp_tables                            | 12744612                                           |
+| Delayed_errors                                | 0                                                  |
+| Delayed_insert_threads                        | 0                                                  |
+| Delayed_writes                                | 0                                                  |
+| Flush_commands                                | 1                                                  |
+| Handler_commit                                | 127179233                                          |
+| Handler_delete                                | 266352                                             |
+| Handler_discover                              | 0                                                  |
+| Handler_external_lock                         | 721632368                                          |
+| Handler_mrr_init                              | 0                                                  |
+| Handler_prepare                               | 23330781                                           |
+| Handler_read_first                            | 952710                                             |
+| Handler_read_key                              | 16963089547                                        |
+| Handler_read_last                             | 2441                                               |
+| Handler_read_next                             | 22094713894                                        |
+| Handler_read_prev                             | 7214456                                            |
+| Handler_read_rnd                              | 140990906                                          |
+| Handler_read_rnd_next                         | 1296981983                                         |
+| Handler_rollback                              | 1837                                               |
+| Handler_savepoint                             | 0                                                  |
+| Handler_savepoint_rollback                    | 0                                                  |
+| Handler_update                                | 1386108672                                         |
+| Handler_write                                 | 901826902                                          |
+| Innodb_buffer_pool_dump_status                | not started                                        |
+| Innodb_buffer_pool_load_status                | not started                                        |
+| Innodb_background_log_sync                    | 50548                                              |
+| Innodb_buffer_pool_pages_data                 | 4175492                                            |
+| Innodb_buffer_pool_bytes_data                 | 68411260928                                        |
+| Innodb_buffer_pool_pages_dirty                | 86716                                              |
+| Innodb_buffer_pool_bytes_dirty                | 1420754944                                         |
+| Innodb_buffer_pool_pages_flushed              | 4789658                                            |
+| Innodb_buffer_pool_pages_LRU_flushed          | 0                                                  |
+| Innodb_buffer_pool_pages_free                 | 11768401                                           |
+| Innodb_buffer_pool_pages_made_not_young       | 0                                                  |
+| Innodb_buffer_pool_pages_made_young           | 2599                                               |
+| Innodb_buffer_pool_pages_misc                 | 56099                                              |
+| Innodb_buffer_pool_pages_old                  | 1541179                                            |
+| Innodb_buffer_pool_pages_total                | 15999992                                           |
+| Innodb_buffer_pool_read_ahead_rnd             | 0                                                  |
+| Innodb_buffer_pool_read_ahead                 | 5701                                               |
+| Innodb_buffer_pool_read_ahead_evicted         | 0                                                  |
+| Innodb_buffer_pool_read_requests              | 90370625297                                        |
+| Innodb_buffer_pool_reads                      | 3032436                                            |
+| Innodb_buffer_pool_wait_free                  | 0                                                  |
+| Innodb_buffer_pool_write_requests             | 665481209                                          |
+| Innodb_checkpoint_age                         | 533727643                                          |
+| Innodb_checkpoint_max_age                     | 1738750649                                         |
+| Innodb_data_fsyncs                            | 570321                                             |
+| Innodb_data_pending_fsyncs                    | 0                                                  |
+| Innodb_data_pending_reads                     | 0                                                  |
+| Innodb_data_pending_writes                    | 0                                                  |
+| Innodb_data_read                              | 49782231040                                        |
+| Innodb_data_reads                             | 3042813                                            |
+| Innodb_data_writes                            | 8199483                                            |
+| Innodb_data_written                           | 187909578752                                       |
+| Innodb_dblwr_pages_written                    | 4789658                                            |
+| Innodb_dblwr_writes                           | 166322                                             |
+| Innodb_deadlocks                              | 0                                                  |
+| Innodb_have_atomic_builtins                   | ON                                                 |
+| Innodb_history_list_length                    | 1181                                               |
+| Innodb_ibuf_discarded_delete_marks            | 0                                                  |
+| Innodb_ibuf_discarded_deletes                 | 0                                                  |
+| Innodb_ibuf_discarded_inserts                 | 0                                                  |
+| Innodb_ibuf_free_list                         | 34385                                              |
+| Innodb_ibuf_merged_delete_marks               | 255                                                |
+| Innodb_ibuf_merged_deletes                    | 1                                                  |
+| Innodb_ibuf_merged_inserts                    | 33616                                              |
+| Innodb_ibuf_merges                            | 5039                                               |
+| Innodb_ibuf_segment_size                      | 34387                                              |
+| Innodb_ibuf_size                              | 1                                                  |
+| Innodb_log_waits                              | 0                                                  |
+| Innodb_log_write_requests                     | 60787514                                           |
+| Innodb_log_writes                             | 3197794                                            |
+| Innodb_lsn_current                            | 13286364623919                                     |
+| Innodb_lsn_flushed                            | 13286363941985                                     |
+| Innodb_lsn_last_checkpoint                    | 13285830896276                                     |
+| Innodb_master_thread_active_loops             | 50518                                              |
+| Innodb_master_thread_idle_loops               | 30                                                 |
+| Innodb_max_trx_id                             | 10298604222                                        |
+| Innodb_mem_adaptive_hash                      | 5539021728                                         |
+| Innodb_mem_dictionary                         | 1162012993                                         |
+| Innodb_mem_total                              | 268288000000                                       |
+| Innodb_mutex_os_waits                         | 257437                                             |
+| Innodb_mutex_spin_rounds                      | 59988196                                           |
+| Innodb_mutex_spin_waits                       | 97486847                                           |
+| Innodb_oldest_view_low_limit_trx_id           | 10298585389                                        |
+| Innodb_os_log_fsyncs                          | 77015                                              |
+| Innodb_os_log_pending_fsyncs                  | 0                                                  |
+| Innodb_os_log_pending_writes                  | 0                                                  |
+| Innodb_os_log_written                         | 30958700544                                        |
+| Innodb_page_size                              | 16384                                              |
+| Innodb_pages_created                          | 1133056                                            |
+| Innodb_pages_read                             | 3042436                                            |
+| Innodb_pages_written                          | 4789658                                            |
+| Innodb_purge_trx_id                           | 10298585424                                        |
+| Innodb_purge_undo_no                          | 0                                                  |
+| Innodb_row_lock_current_waits                 | 0                                                  |
+| Innodb_current_row_locks                      | 2                                                  |
+| Innodb_row_lock_time                          | 8907                                               |
+| Innodb_row_lock_time_avg                      | 9                                                  |
+| Innodb_row_lock_time_max                      | 2514                                               |
+| Innodb_row_lock_waits                         | 966                                                |
+| Innodb_rows_deleted                           | 266430                                             |
+| Innodb_rows_inserted                          | 206532943                                          |
+| Innodb_rows_read                              | 30852953238                                        |
+| Innodb_rows_updated                           | 5047478                                            |
+| Innodb_num_open_files                         | 1024                                               |
+| Innodb_read_views_memory                      | 14880                                              |
+| Innodb_descriptors_memory                     | 8000                                               |
+| Innodb_s_lock_os_waits                        | 137107                                             |
+| Innodb_s_lock_spin_rounds                     | 36479525                                           |
+| Innodb_s_lock_spin_waits                      | 40203178                                           |
+| Innodb_truncated_status_writes                | 0                                                  |
+| Innodb_available_undo_logs                    | 128                                                |
+| Innodb_x_lock_os_waits                        | 53521                                              |
+| Innodb_x_lock_spin_rounds                     | 160801922                                          |
+| Innodb_x_lock_spin_waits                      | 5042610                                            |
+| Key_blocks_not_flushed                        | 6649                                               |
+| Key_blocks_unused                             | 13328                                              |
+| Key_blocks_used                               | 10119                                              |
+| Key_read_requests                             | 2521129091                                         |
+| Key_reads                                     | 2                                                  |
+| Key_write_requests                            | 398400037                                          |
+| Key_writes                                    | 0                                                  |
+| Last_query_cost                               | 0.000000                                           |
+| Last_query_partial_plans                      | 0                                                  |
+| Max_statement_time_exceeded                   | 0                                                  |
+| Max_statement_time_set                        | 0                                                  |
+| Max_statement_time_set_failed                 | 0                                                  |
+| Max_used_connections                          | 128                                                |
+| Not_flushed_delayed_rows                      | 0                                                  |
+| Open_files                                    | 67                                                 |
+| Open_streams                                  | 0                                                  |
+| Open_table_definitions                        | 189                                                |
+| Open_tables                                   | 402                                                |
+| Opened_files                                  | 10176166                                           |
+| Opened_table_definitions                      | 189                                                |
+| Opened_tables                                 | 409                                                |
+| Performance_schema_accounts_lost              | 0                                                  |
+| Performance_schema_cond_classes_lost          | 0                                                  |
+| Performance_schema_cond_instances_lost        | 0                                                  |
+| Performance_schema_digest_lost                | 0                                                  |
+| Performance_schema_file_classes_lost          | 0                                                  |
+| Performance_schema_file_handles_lost          | 0                                                  |
+| Performance_schema_file_instances_lost        | 0                                                  |
+| Performance_schema_hosts_lost                 | 0                                                  |
+| Performance_schema_locker_lost                | 0                                                  |
+| Performance_schema_mutex_classes_lost         | 0                                                  |
+| Performance_schema_mutex_instances_lost       | 0                                                  |
+| Performance_schema_rwlock_classes_lost        | 0                                                  |
+| Performance_schema_rwlock_instances_lost      | 0                                                  |
+| Performance_schema_session_connect_attrs_lost | 0                                                  |
+| Performance_schema_socket_classes_lost        | 0                                                  |
+| Performance_schema_socket_instances_lost      | 0                                                  |
+| Performance_schema_stage_classes_lost         | 0                                                  |
+| Performance_schema_statement_classes_lost     | 0                                                  |
+| Performance_schema_table_handles_lost         | 0                                                  |
+| Performance_schema_table_instances_lost       | 0                                                  |
+| Performance_schema_thread_classes_lost        | 0                                                  |
+| Performance_schema_thread_instances_lost      | 0                                                  |
+| Performance_schema_users_lost                 | 0                                                  |
+| Prepared_stmt_count                           | 0                                                  |
+| Qcache_free_blocks                            | 0                                                  |
+| Qcache_free_memory                            | 0                                                  |
+| Qcache_hits                                   | 0                                                  |
+| Qcache_inserts                                | 0                                                  |
+| Qcache_lowmem_prunes                          | 0                                                  |
+| Qcache_not_cached                             | 0                                                  |
+| Qcache_queries_in_cache                       | 0                                                  |
+| Qcache_total_blocks                           | 0                                                  |
+| Queries                                       | 65243497                                           |
+| Questions                                     | 65211244                                           |
+| Rsa_public_key                                |                                                    |
+| Select_full_join                              | 843                                                |
+| Select_full_range_join                        | 2                                                  |
+| Select_range                                  | 2136874                                            |
+| Select_range_check                            | 0                                                  |
+| Select_scan                                   | 1540484                                            |
+| Slave_heartbeat_period                        | 0.000                                              |
+| Slave_last_heartbeat                          |                                                    |
+| Slave_open_temp_tables                        | 0                                                  |
+| Slave_received_heartbeats                     | 0                                                  |
+| Slave_retried_transactions                    | 0                                                  |
+| Slave_running                                 | OFF                                                |
+| Slow_launch_threads                           | 0                                                  |
+| Slow_queries                                  | 1210873                                            |
+| Sort_merge_passes                             | 1110                                               |
+| Sort_range                                    | 5702703                                            |
+| Sort_rows                                     | 206827039                                          |
+| Sort_scan                                     | 123674960                                          |
+| Ssl_accept_renegotiates                       | 0                                                  |
+| Ssl_accepts                                   | 0                                                  |
+| Ssl_callback_cache_hits                       | 0                                                  |
+| Ssl_cipher                                    |                                                    |
+| Ssl_cipher_list                               |                                                    |
+| Ssl_client_connects                           | 0                                                  |
+| Ssl_connect_renegotiates                      | 0                                                  |
+| Ssl_ctx_verify_depth                          | 0                                                  |
+| Ssl_ctx_verify_mode                           | 0                                                  |
+| Ssl_default_timeout                           | 0                                                  |
+| Ssl_finished_accepts                          | 0                                                  |
+| Ssl_finished_connects                         | 0                                                  |
+| Ssl_server_not_after                          |                                                    |
+| Ssl_server_not_before                         |                                                    |
+| Ssl_session_cache_hits                        | 0                                                  |
+| Ssl_session_cache_misses                      | 0                                                  |
+| Ssl_session_cache_mode                        | NONE                                               |
+| Ssl_session_cache_overflows                   | 0                                                  |
+| Ssl_session_cache_size                        | 0                                                  |
+| Ssl_session_cache_timeouts                    | 0                                                  |
+| Ssl_sessions_reused                           | 0                                                  |
+| Ssl_used_session_cache_entries                | 0                                                  |
+| Ssl_verify_depth                              | 0                                                  |
+| Ssl_verify_mode                               | 0                                                  |
+| Ssl_version                                   |                                                    |
+| Table_locks_immediate                         | 355245612                                          |
+| Table_locks_waited                            | 0                                                  |
+| Table_open_cache_hits                         | 120923894                                          |
+| Table_open_cache_misses                       | 409                                                |
+| Table_open_cache_overflows                    | 0                                                  |
+| Tc_log_max_pages_used                         | 0                                                  |
+| Tc_log_page_size                              | 0                                                  |
+| Tc_log_page_waits                             | 0                                                  |
+| Threadpool_idle_threads                       | 0                                                  |
+| Threadpool_threads                            | 0                                                  |
+| Threads_cached                                | 11                                                 |
+| Threads_connected                             | 117                                                |
+| Threads_created                               | 149                                                |
+| Threads_running                               | 7                                                  |
+| Uptime                                        | 50683                                              |
+| Uptime_since_flush_status                     | 50683                                              |
+| wsrep_local_state_uuid                        | 32d4b8b3-7efb-11e3-b8d6-971666ffe06d               |
+| wsrep_protocol_version                        | 6                                                  |
+| wsrep_last_committed                          | 1356722569                                         |
+| wsrep_replicated                              | 3119304                                            |
+| wsrep_replicated_bytes                        | 10641997157                                        |
+| wsrep_repl_keys                               | 425578788                                          |
+| wsrep_repl_keys_bytes                         | 3477370818                                         |
+| wsrep_repl_data_bytes                         | 6964990883                                         |
+| wsrep_repl_other_bytes                        | 0                                                  |
+| wsrep_received                                | 245849                                             |
+| wsrep_received_bytes                          | 1968113                                            |
+| wsrep_local_commits                           | 3119304                                            |
+| wsrep_local_cert_failures                     | 0                                                  |
+| wsrep_local_replays                           | 0                                                  |
+| wsrep_local_send_queue                        | 0                                                  |
+| wsrep_local_send_queue_max                    | 14                                                 |
+| wsrep_local_send_queue_min                    | 0                                                  |
+| wsrep_local_send_queue_avg                    | 0.003886                                           |
+| wsrep_local_recv_queue                        | 0                                                  |
+| wsrep_local_recv_queue_max                    | 8                                                  |
+| wsrep_local_recv_queue_min                    | 0                                                  |
+| wsrep_local_recv_queue_avg                    | 0.002164                                           |
+| wsrep_local_cached_downto                     | 1356407818                                         |
+| wsrep_flow_control_paused_ns                  | 4367461719                                         |
+| wsrep_flow_control_paused                     | 0.000086                                           |
+| wsrep_flow_control_sent                       | 0                                                  |
+| wsrep_flow_control_recv                       | 81                                                 |
+| wsrep_cert_deps_distance                      | 20.904408                                          |
+| wsrep_apply_oooe                              | 0.015480                                           |
+| wsrep_apply_oool                              | 0.000020                                           |
+| wsrep_apply_window                            | 1.015859                                           |
+| wsrep_commit_oooe                             | 0.000000                                           |
+| wsrep_commit_oool                             | 0.000000                                           |
+| wsrep_commit_window                           | 1.000289                                           |
+| wsrep_local_state                             | 4                                                  |
+| wsrep_local_state_comment                     | Synced                                             |
+| wsrep_cert_index_size                         | 1763                                               |
+| wsrep_causal_reads                            | 0                                                  |
+| wsrep_cert_interval                           | 0.042518                                           |
+| wsrep_incoming_addresses                      | 10.5.161.41:3306,10.5.161.42:3306,10.5.161.40:3306 |
+| wsrep_evs_repl_latency                        | 0.000230418/0.000474604/0.0013453/8.84116e-05/3150 |
+| wsrep_cluster_conf_id                         | 117                                                |
+| wsrep_cluster_size                            | 3                                                  |
+| wsrep_cluster_state_uuid                      | 32d4b8b3-7efb-11e3-b8d6-971666ffe06d               |
+| wsrep_cluster_status                          | Primary                                            |
+| wsrep_connected                               | ON                                                 |
+| wsrep_local_bf_aborts                         | 0                                                  |
+| wsrep_local_index                             | 2                                                  |
+| wsrep_provider_name                           | Galera                                             |
+| wsrep_provider_vendor                         | Codership Oy <info@codership.com>                  |
+| wsrep_provider_version                        | 3.7(r7f44a18)                                      |
+| wsrep_ready                                   | ON                                                 |
++-----------------------------------------------+----------------------------------------------------+
+
+TS 1414170881.007 2014-10-24 13:34:41
++-----------------------------------------------+----------------------------------------------------+
+| Variable_name                                 | Value                                              |
++-----------------------------------------------+----------------------------------------------------+
+| Aborted_clients                               | 3                                                  |
+| Aborted_connects                              | 21265                                              |
+| Binlog_snapshot_file                          | binlog.002444                                      |
+| Binlog_snapshot_position                      | 596612182                                          |
+| Binlog_cache_disk_use                         | 3146                                               |
+| Binlog_cache_use                              | 3119853                                            |
+| Binlog_stmt_cache_disk_use                    | 0                                                  |
+| Binlog_stmt_cache_use                         | 0                                                  |
+| Bytes_received                                | 19096078726                                        |
+| Bytes_sent                                    | 145087283580                                       |
+| Com_admin_commands                            | 32253                                              |
+| Com_assign_to_keycache                        | 0                                                  |
+| Com_alter_db                                  | 0                                                  |
+| Com_alter_db_upgrade                          | 0                                                  |
+| Com_alter_event                               | 0                                                  |
+| Com_alter_function                            | 0                                                  |
+| Com_alter_procedure                           | 0                                                  |
+| Com_alter_server                              | 0                                                  |
+| Com_alter_table                               | 0                                                  |
+| Com_alter_tablespace                          | 0                                                  |
+| Com_alter_user                                | 0                                                  |
+| Com_analyze                                   | 0                                                  |
+| Com_begin                                     | 4000579                                            |
+| Com_binlog                                    | 0                                                  |
+| Com_call_procedure                            | 0                                                  |
+| Com_change_db                                 | 12475                                              |
+| Com_change_master                             | 0                                                  |
+| Com_check                                     | 0                                                  |
+| Com_checksum                                  | 0                                                  |
+| Com_commit                                    | 3999873                                            |
+| Com_create_db                                 | 0                                                  |
+| Com_create_event                              | 0                                                  |
+| Com_create_function                           | 0                                                  |
+| Com_create_index                              | 0                                                  |
+| Com_create_procedure                          | 0                                                  |
+| Com_create_server                             | 0                                                  |
+| Com_create_table                              | 0                                                  |
+| Com_create_trigger                            | 0                                                  |
+| Com_create_udf                                | 0                                                  |
+| Com_create_user                               | 0                                                  |
+| Com_create_view                               | 0                                                  |
+| Com_dealloc_sql                               | 0                                                  |
+| Com_delete                                    | 216527                                             |
+| Com_delete_multi                              | 0                                                  |
+| Com_do                                        | 0                                                  |
+| Com_drop_db                                   | 0                                                  |
+| Com_drop_event                                | 0                                                  |
+| Com_drop_function                             | 0                                                  |
+| Com_drop_index                                | 0                                                  |
+| Com_drop_procedure                            | 0                                                  |
+| Com_drop_server                               | 0                                                  |
+| Com_drop_table                                | 0                                                  |
+| Com_drop_trigger                              | 0                                                  |
+| Com_drop_user                                 | 0                                                  |
+| Com_drop_view                                 | 0                                                  |
+| Com_empty_query                               | 0                                                  |
+| Com_execute_sql                               | 0                                                  |
+| Com_flush                                     | 1863                                               |
+| Com_get_diagnostics                           | 0                                                  |
+| Com_grant                                     | 0                                                  |
+| Com_ha_close                                  | 0                                                  |
+| Com_ha_open                                   | 0                                                  |
+| Com_ha_read                                   | 0                                                  |
+| Com_help                                      | 1                                                  |
+| Com_insert                                    | 2569151                                            |
+| Com_insert_select                             | 0                                                  |
+| Com_install_plugin                            | 0                                                  |
+| Com_kill                                      | 0                                                  |
+| Com_load                                      | 0                                                  |
+| Com_lock_tables                               | 0                                                  |
+| Com_lock_tables_for_backup                    | 0                                                  |
+| Com_lock_binlog_for_backup                    | 0                                                  |
+| Com_optimize                                  | 0                                                  |
+| Com_preload_keys                              | 0                                                  |
+| Com_prepare_sql                               | 0                                                  |
+| Com_purge                                     | 0                                                  |
+| Com_purge_before_date                         | 0                                                  |
+| Com_purge_archived                            | 0                                                  |
+| Com_purge_archived_before_date                | 0                                                  |
+| Com_release_savepoint                         | 0                                                  |
+| Com_rename_table                              | 0                                                  |
+| Com_rename_user                               | 0                                                  |
+| Com_repair                                    | 0                                                  |
+| Com_replace                                   | 0                                                  |
+| Com_replace_select                            | 0                                                  |
+| Com_reset                                     | 0                                                  |
+| Com_resignal                                  | 0                                                  |
+| Com_revoke                                    | 0                                                  |
+| Com_revoke_all                                | 0                                                  |
+| Com_rollback                                  | 701                                                |
+| Com_rollback_to_savepoint                     | 0                                                  |
+| Com_savepoint                                 | 0                                                  |
+| Com_select                                    | 49707483                                           |
+| Com_set_option                                | 303615                                             |
+| Com_signal                                    | 0                                                  |
+| Com_show_binlog_events                        | 0                                                  |
+| Com_show_binlogs                              | 0                                                  |
+| Com_show_charsets                             | 0                                                  |
+| Com_show_client_statistics                    | 0                                                  |
+| Com_show_collations                           | 0                                                  |
+| Com_show_create_db                            | 0                                                  |
+| Com_show_create_event                         | 0                                                  |
+| Com_show_create_func                          | 0                                                  |
+| Com_show_create_proc                          | 0                                                  |
+| Com_show_create_table                         | 0                                                  |
+| Com_show_create_trigger                       | 0                                                  |
+| Com_show_databases                            | 0                                                  |
+| Com_show_engine_logs                          | 0                                                  |
+| Com_show_engine_mutex                         | 0                                                  |
+| Com_show_engine_status                        | 0                                                  |
+| Com_show_events                               | 0                                                  |
+| Com_show_errors                               | 0                                                  |
+| Com_show_fields                               | 0                                                  |
+| Com_show_function_code                        | 0                                                  |
+| Com_show_function_status                      | 0                                                  |
+| Com_show_grants                               | 0                                                  |
+| Com_show_index_statistics                     | 0                                                  |
+| Com_show_keys                                 | 0                                                  |
+| Com_show_master_status                        | 1687                                               |
+| Com_show_open_tables                          | 0                                                  |
+| Com_show_plugins                              | 1                                                  |
+| Com_show_privileges                           | 0                                                  |
+| Com_show_procedure_code                       | 0                                                  |
+| Com_show_procedure_status                     | 0                                                  |
+| Com_show_processlist                          | 10121                                              |
+| Com_show_profile                              | 0                                                  |
+| Com_show_profiles                             | 0                                                  |
+| Com_show_relaylog_events                      | 0                                                  |
+| Com_show_slave_hosts                          | 0                                                  |
+| Com_show_slave_status                         | 1687                                               |
+| Com_show_slave_status_nolock                  | 0                                                  |
+| Com_show_status                               | 72594                                              |
+| Com_show_storage_engines                      | 0                                                  |
+| Com_show_table_statistics                     | 0                                                  |
+| Com_show_table_status                         | 0                                                  |
+| Com_show_tables                               | 282654                                             |
+| Com_show_thread_statistics                    | 0                                                  |
+| Com_show_triggers                             | 0                                                  |
+| Com_show_user_statistics                      | 0                                                  |
+| Com_show_variables                            | 4753                                               |
+| Com_show_warnings                             | 0                                                  |
+| Com_slave_start                               | 0                                                  |
+| Com_slave_stop                                | 0                                                  |
+| Com_stmt_close                                | 0                                                  |
+| Com_stmt_execute                              | 0                                                  |
+| Com_stmt_fetch                                | 0                                                  |
+| Com_stmt_prepare                              | 0                                                  |
+| Com_stmt_reprepare                            | 0                                                  |
+| Com_stmt_reset                                | 0                                                  |
+| Com_stmt_send_long_data                       | 0                                                  |
+| Com_truncate                                  | 0                                                  |
+| Com_uninstall_plugin                          | 0                                                  |
+| Com_unlock_binlog                             | 0                                                  |
+| Com_unlock_tables                             | 0                                                  |
+| Com_update                                    | 3662398                                            |
+| Com_update_multi                              | 0                                                  |
+| Com_xa_commit                                 | 0                                                  |
+| Com_xa_end                                    | 0                                                  |
+| Com_xa_prepare                                | 0                                                  |
+| Com_xa_recover                                | 0                                                  |
+| Com_xa_rollback                               | 0                                                  |
+| Com_xa_start                                  | 0                                                  |
+| Compression                                   | OFF                                                |
+| Connection_errors_accept                      | 0                                                  |
+| Connection_errors_internal                    | 0                                                  |
+| Connection_errors_max_connections             | 0                                                  |
+| Connection_errors_peer_address                | 0                                                  |
+| Connection_errors_select                      | 0                                                  |
+| Connection_errors_tcpwrap                     | 0                                                  |
+| Connections                                   | 375656                                             |
+| Created_tmp_disk_tables                       | 2543229                                            |
+| Created_tmp_files                             | 1221                                               |
+| Created_tmp_tables                            | 12744983                                           |
+| Delayed_errors                                | 0                                                  |
+| Delayed_insert_threads                        | 0                                                  |
+| Delayed_writes                                | 0                                                  |
+| Flush_commands                                | 1                                                  |
+| Handler_commit                                | 127182924                                          |
+| Handler_delete                                | 266356                                             |
+| Handler_discover                              | 0                                                  |
+| Handler_external_lock                         | 721646952                                          |
+| Handler_mrr_init                              | 0                                                  |
+| Handler_prepare                               | 23331630                                           |
+| Handler_read_first                            | 952729                                             |
+| Handler_read_key                              | 16963294629                                        |
+| Handler_read_last                             | 2441                                               |
+| Handler_read_next                             | 22094864141                                        |
+| Handler_read_prev                             | 7214456                                            |
+| Handler_read_rnd                              | 140993497                                          |
+| Handler_read_rnd_next                         | 1297040450                                         |
+| Handler_rollback                              | 1837                                               |
+| Handler_savepoint                             | 0                                                  |
+| Handler_savepoint_rollback                    | 0                                                  |
+| Handler_update                                | 1386108837                                         |
+| Handler_write                                 | 901857098                                          |
+| Innodb_buffer_pool_dump_status                | not started                                        |
+| Innodb_buffer_pool_load_status                | not started                                        |
+| Innodb_background_log_sync                    | 50549                                              |
+| Innodb_buffer_pool_pages_data                 | 4175521                                            |
+| Innodb_buffer_pool_bytes_data                 | 68411736064                                        |
+| Innodb_buffer_pool_pages_dirty                | 86767                                              |
+| Innodb_buffer_pool_bytes_dirty                | 1421590528                                         |
+| Innodb_buffer_pool_pages_flushed              | 4789744                                            |
+| Innodb_buffer_pool_pages_LRU_flushed          | 0                                                  |
+| Innodb_buffer_pool_pages_free                 | 11768372                                           |
+| Innodb_buffer_pool_pages_made_not_young       | 0                                                  |
+| Innodb_buffer_pool_pages_made_young           | 2599                                               |
+| Innodb_buffer_pool_pages_misc                 | 56099                                              |
+| Innodb_buffer_pool_pages_old                  | 1541190                                            |
+| Innodb_buffer_pool_pages_total                | 15999992                                           |
+| Innodb_buffer_pool_read_ahead_rnd             | 0                                                  |
+| Innodb_buffer_pool_read_ahead                 | 5701                                               |
+| Innodb_buffer_pool_read_ahead_evicted         | 0                                                  |
+| Innodb_buffer_pool_read_requests              | 90371487780                                        |
+| Innodb_buffer_pool_reads                      | 3032436                                            |
+| Innodb_buffer_pool_wait_free                  | 0                                                  |
+| Innodb_buffer_pool_write_requests             | 665499210                                          |
+| Innodb_checkpoint_age                         | 532036659                                          |
+| Innodb_checkpoint_max_age                     | 1738750649                                         |
+| Innodb_data_fsyncs                            | 570330                                             |
+| Innodb_data_pending_fsyncs                    | 0                                                  |
+| Innodb_data_pending_reads                     | 0                                                  |
+| Innodb_data_pending_writes                    | 0                                                  |
+| Innodb_data_read                              | 49782231040                                        |
+| Innodb_data_reads                             | 3042813                                            |
+| Innodb_data_writes                            | 8199685                                            |
+| Innodb_data_written                           | 187913254912                                       |
+| Innodb_dblwr_pages_written                    | 4789744                                            |
+| Innodb_dblwr_writes                           | 166323                                             |
+| Innodb_deadlocks                              | 0                                                  |
+| Innodb_have_atomic_builtins                   | ON                                                 |
+| Innodb_history_list_length                    | 1256                                               |
+| Innodb_ibuf_discarded_delete_marks            | 0                                                  |
+| Innodb_ibuf_discarded_deletes                 | 0                                                  |
+| Innodb_ibuf_discarded_inserts                 | 0                                                  |
+| Innodb_ibuf_free_list                         | 34385                                              |
+| Innodb_ibuf_merged_delete_marks               | 255                                                |
+| Innodb_ibuf_merged_deletes                    | 1                                                  |
+| Innodb_ibuf_merged_inserts                    | 33616                                              |
+| Innodb_ibuf_merges                            | 5039                                               |
+| Innodb_ibuf_segment_size                      | 34387                                              |
+| Innodb_ibuf_size                              | 1                                                  |
+| Innodb_log_waits                              | 0                                                  |
+| Innodb_log_write_requests                     | 60789158                                           |
+| Innodb_log_writes                             | 3197907                                            |
+| Innodb_lsn_current                            | 13286365423828                                     |
+| Innodb_lsn_flushed                            | 13286364723900                                     |
+| Innodb_lsn_last_checkpoint                    | 13285833387169                                     |
+| Innodb_master_thread_active_loops             | 50519                                              |
+| Innodb_master_thread_idle_loops               | 30                                                 |
+| Innodb_max_trx_id                             | 10298605332                                        |
+| Innodb_mem_adaptive_hash                      | 5539021728                                         |
+| Innodb_mem_dictionary                         | 1162012993                                         |
+| Innodb_mem_total                              | 268288000000                                       |
+| Innodb_mutex_os_waits                         | 257452                                             |
+| Innodb_mutex_spin_rounds                      | 59988838                                           |
+| Innodb_mutex_spin_waits                       | 97486922                                           |
+| Innodb_oldest_view_low_limit_trx_id           | 10298585389                                        |
+| Innodb_os_log_fsyncs                          | 77018                                              |
+| Innodb_os_log_pending_fsyncs                  | 0                                                  |
+| Innodb_os_log_pending_writes                  | 0                                                  |
+| Innodb_os_log_written                         | 30959558144                                        |
+| Innodb_page_size                              | 16384                                              |
+| Innodb_pages_created                          | 1133085                                            |
+| Innodb_pages_read                             | 3042436                                            |
+| Innodb_pages_written                          | 4789744                                            |
+| Innodb_purge_trx_id                           | 10298585424                                        |
+| Innodb_purge_undo_no                          | 0                                                  |
+| Innodb_row_lock_current_waits                 | 0                                                  |
+| Innodb_current_row_locks                      | 2                                                  |
+| Innodb_row_lock_time                          | 8907                                               |
+| Innodb_row_lock_time_avg                      | 9                                                  |
+| Innodb_row_lock_time_max                      | 2514                                               |
+| Innodb_row_lock_waits                         | 966                                                |
+| Innodb_rows_deleted                           | 266434                                             |
+| Innodb_rows_inserted                          | 206538489                                          |
+| Innodb_rows_read                              | 30853228967                                        |
+| Innodb_rows_updated                           | 5047643                                            |
+| Innodb_num_open_files                         | 1024                                               |
+| Innodb_read_views_memory                      | 14752                                              |
+| Innodb_descriptors_memory                     | 8000                                               |
+| Innodb_s_lock_os_waits                        | 137108                                             |
+| Innodb_s_lock_spin_rounds                     | 36479891                                           |
+| Innodb_s_lock_spin_waits                      | 40203674                                           |
+| Innodb_truncated_status_writes                | 0                                                  |
+| Innodb_available_undo_logs                    | 128                                                |
+| Innodb_x_lock_os_waits                        | 53521                                              |
+| Innodb_x_lock_spin_rounds                     | 160804210                                          |
+| Innodb_x_lock_spin_waits                      | 5042693                                            |
+| Key_blocks_not_flushed                        | 6883                                               |
+| Key_blocks_unused                             | 13094                                              |
+| Key_blocks_used                               | 10119                                              |
+| Key_read_requests                             | 2521308636                                         |
+| Key_reads                                     | 2                                                  |
+| Key_write_requests                            | 398423897                                          |
+| Key_writes                                    | 0                                                  |
+| Last_query_cost                               | 0.000000                                           |
+| Last_query_partial_plans                      | 0                                                  |
+| Max_statement_time_exceeded                   | 0                                                  |
+| Max_statement_time_set                        | 0                                                  |
+| Max_statement_time_set_failed                 | 0                                                  |
+| Max_used_connections                          | 128                                                |
+| Not_flushed_delayed_rows                      | 0                                                  |
+| Open_files                                    | 67                                                 |
+| Open_streams                                  | 0                                                  |
+| Open_table_definitions                        | 189                                                |
+| Open_tables                                   | 402                                                |
+| Opened_files                                  | 10176442                                           |
+| Opened_table_definitions                      | 189                                                |
+| Opened_tables                                 | 409                                                |
+| Performance_schema_accounts_lost              | 0                                                  |
+| Performance_schema_cond_classes_lost          | 0                                                  |
+| Performance_schema_cond_instances_lost        | 0                                                  |
+| Performance_schema_digest_lost                | 0                                                  |
+| Performance_schema_file_classes_lost          | 0                                                  |
+| Performance_schema_file_handles_lost          | 0                                                  |
+| Performance_schema_file_instances_lost        | 0                                                  |
+| Performance_schema_hosts_lost                 | 0                                                  |
+| Performance_schema_locker_lost                | 0                                                  |
+| Performance_schema_mutex_classes_lost         | 0                                                  |
+| Performance_schema_mutex_instances_lost       | 0                                                  |
+| Performance_schema_rwlock_classes_lost        | 0                                                  |
+| Performance_schema_rwlock_instances_lost      | 0                                                  |
+| Performance_schema_session_connect_attrs_lost | 0                                                  |
+| Performance_schema_socket_classes_lost        | 0                                                  |
+| Performance_schema_socket_instances_lost      | 0                                                  |
+| Performance_schema_stage_classes_lost         | 0                                                  |
+| Performance_schema_statement_classes_lost     | 0                                                  |
+| Performance_schema_table_handles_lost         | 0                                                  |
+| Performance_schema_table_instances_lost       | 0                                                  |
+| Performance_schema_thread_classes_lost        | 0                                                  |
+| Performance_schema_thread_instances_lost      | 0                                                  |
+| Performance_schema_users_lost                 | 0                                                  |
+| Prepared_stmt_count                           | 0                                                  |
+| Qcache_free_blocks                            | 0                                                  |
+| Qcache_free_memory                            | 0                                                  |
+| Qcache_hits                                   | 0                                                  |
+| Qcache_inserts                                | 0                                                  |
+| Qcache_lowmem_prunes                          | 0                                                  |
+| Qcache_not_cached                             | 0                                                  |
+| Qcache_queries_in_cache                       | 0                                                  |
+| Qcache_total_blocks                           | 0                                                  |
+| Queries                                       | 65245332                                           |
+| Questions                                     | 65213079                                           |
+| Rsa_public_key                                |                                                    |
+| Select_full_join                              | 843                                                |
+| Select_full_range_join                        | 2                                                  |
+| Select_range                                  | 2136960                                            |
+| Select_range_check                            | 0                                                  |
+| Select_scan                                   | 1540515                                            |
+| Slave_heartbeat_period                        | 0.000                                              |
+| Slave_last_heartbeat                          |                                                    |
+| Slave_open_temp_tables                        | 0                                                  |
+| Slave_received_heartbeats                     | 0                                                  |
+| Slave_retried_transactions                    | 0                                                  |
+| Slave_running                                 | OFF                                                |
+| Slow_launch_threads                           | 0                                                  |
+| Slow_queries                                  | 1210897                                            |
+| Sort_merge_passes                             | 1110                                               |
+| Sort_range                                    | 5702832                                            |
+| Sort_rows                                     | 206831211                                          |
+| Sort_scan                                     | 123674961                                          |
+| Ssl_accept_renegotiates                       | 0                                                  |
+| Ssl_accepts                                   | 0                                                  |
+| Ssl_callback_cache_hits                       | 0                                                  |
+| Ssl_cipher                                    |                                                    |
+| Ssl_cipher_list                               |                                                    |
+| Ssl_client_connects                           | 0                                                  |
+| Ssl_connect_renegotiates                      | 0                                                  |
+| Ssl_ctx_verify_depth                          | 0                                                  |
+| Ssl_ctx_verify_mode                           | 0                                                  |
+| Ssl_default_timeout                           | 0                                                  |
+| Ssl_finished_accepts                          | 0                                                  |
+| Ssl_finished_connects                         | 0                                                  |
+| Ssl_server_not_after                          |                                                    |
+| Ssl_server_not_before                         |                                                    |
+| Ssl_session_cache_hits                        | 0                                                  |
+| Ssl_session_cache_misses                      | 0                                                  |
+| Ssl_session_cache_mode                        | NONE                                               |
+| Ssl_session_cache_overflows                   | 0                                                  |
+| Ssl_session_cache_size                        | 0                                                  |
+| Ssl_session_cache_timeouts                    | 0                                                  |
+| Ssl_sessions_reused                           | 0                                                  |
+| Ssl_used_session_cache_entries                | 0                                                  |
+| Ssl_verify_depth                              | 0                                                  |
+| Ssl_verify_mode                               | 0                                                  |
+| Ssl_version                                   |                                                    |
+| Table_locks_immediate                         | 355252770                                          |
+| Table_locks_waited                            | 0                                                  |
+| Table_open_cache_hits                         | 120927332                                          |
+| Table_open_cache_misses                       | 409                                                |
+| Table_open_cache_overflows                    | 0                                                  |
+| Tc_log_max_pages_used                         | 0                                                  |
+| Tc_log_page_size                              | 0                                                  |
+| Tc_log_page_waits                             | 0                                                  |
+| Threadpool_idle_threads                       | 0                                                  |
+| Threadpool_threads                            | 0                                                  |
+| Threads_cached                                | 12                                                 |
+| Threads_connected                             | 116                                                |
+| Threads_created                               | 149                                                |
+| Threads_running                               | 4                                                  |
+| Uptime                                        | 50684                                              |
+| Uptime_since_flush_status                     | 50684                                              |
+| wsrep_local_state_uuid                        | 32d4b8b3-7efb-11e3-b8d6-971666ffe06d               |
+| wsrep_protocol_version                        | 6                                                  |
+| wsrep_last_committed                          | 1356722679                                         |
+| wsrep_replicated                              | 3119414                                            |
+| wsrep_replicated_bytes                        | 10642279884                                        |
+| wsrep_repl_keys                               | 425589924                                          |
+| wsrep_repl_keys_bytes                         | 3477462481                                         |
+| wsrep_repl_data_bytes                         | 6965174907                                         |
+| wsrep_repl_other_bytes                        | 0                                                  |
+| wsrep_received                                | 245860                                             |
+| wsrep_received_bytes                          | 1968201                                            |
+| wsrep_local_commits                           | 3119414                                            |
+| wsrep_local_cert_failures                     | 0                                                  |
+| wsrep_local_replays                           | 0                                                  |
+| wsrep_local_send_queue                        | 0                                                  |
+| wsrep_local_send_queue_max                    | 14                                                 |
+| wsrep_local_send_queue_min                    | 0                                                  |
+| wsrep_local_send_queue_avg                    | 0.003886                                           |
+| wsrep_local_recv_queue                        | 0                                                  |
+| wsrep_local_recv_queue_max                    | 8                                                  |
+| wsrep_local_recv_queue_min                    | 0                                                  |
+| wsrep_local_recv_queue_avg                    | 0.002164                                           |
+| wsrep_local_cached_downto                     | 1356407910                                         |
+| wsrep_flow_control_paused_ns                  | 4367461719                                         |
+| wsrep_flow_control_paused                     | 0.000086                                           |
+| wsrep_flow_control_sent                       | 0                                                  |
+| wsrep_flow_control_recv                       | 81                                                 |
+| wsrep_cert_deps_distance                      | 20.904128                                          |
+| wsrep_apply_oooe                              | 0.015481                                           |
+| wsrep_apply_oool                              | 0.000020                                           |
+| wsrep_apply_window                            | 1.015859                                           |
+| wsrep_commit_oooe                             | 0.000000                                           |
+| wsrep_commit_oool                             | 0.000000                                           |
+| wsrep_commit_window                           | 1.000289                                           |
+| wsrep_local_state                             | 4                                                  |
+| wsrep_local_state_comment                     | Synced                                             |
+| wsrep_cert_index_size                         | 1019                                               |
+| wsrep_causal_reads                            | 0                                                  |
+| wsrep_cert_interval                           | 0.042520                                           |
+| wsrep_incoming_addresses                      | 10.5.161.41:3306,10.5.161.42:3306,10.5.161.40:3306 |
+| wsrep_evs_repl_latency                        | 0.000230418/0.000475757/0.0013453/8.78929e-05/3269 |
+| wsrep_cluster_conf_id                         | 117                                                |
+| wsrep_cluster_size                            | 3                                                  |
+| wsrep_cluster_state_uuid                      | 32d4b8b3-7efb-11e3-b8d6-971666ffe06d               |
+| wsrep_cluster_status                          | Primary                                            |
+| wsrep_connected                               | ON                                                 |
+| wsrep_local_bf_aborts                         | 0                                                  |
+| wsrep_local_index                             | 2                                                  |
+| wsrep_provider_name                           | Galera                                             |
+| wsrep_provider_vendor                         | Codership Oy <info@codership.com>                  |
+| wsrep_provider_version                        | 3.7(r7f44a18)                                      |
+| wsrep_ready                                   | ON                                                 |
++-----------------------------------------------+----------------------------------------------------+
+
+
+
+
+